@@ -0,0 +1,98 @@
+package gtree
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v2"
+)
+
+// annotations is descriptions of files keyed by the path relative to the tree's root.
+type annotations map[string]string
+
+// loadAnnotations reads the annotation file.
+// JSON and YAML files are objects which map paths to descriptions.
+// Other files such as '.gtree-annotations' are read by readAnnotationLines.
+func loadAnnotations(filename string) (annotations, error) {
+	var m map[string]interface{}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		b, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to read annotations: %w", err)
+		}
+		if err := json.Unmarshal(b, &m); err != nil {
+			return nil, xerrors.Errorf("failed to parse annotations: %w", err)
+		}
+	case ".yaml", ".yml":
+		b, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to read annotations: %w", err)
+		}
+		if err := yaml.Unmarshal(b, &m); err != nil {
+			return nil, xerrors.Errorf("failed to parse annotations: %w", err)
+		}
+	default:
+		return readAnnotationLines(filename)
+	}
+
+	result := make(annotations, len(m))
+	for path, d := range m {
+		result[normalizeAnnotationPath(path)] = strings.TrimSpace(fmt.Sprint(d))
+	}
+	return result, nil
+}
+
+// readAnnotationLines reads the annotation file whose lines are 'path: description'.
+// Lines starting with '#' are ignored.
+func readAnnotationLines(filename string) (annotations, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to open annotations: %w", err)
+	}
+	defer f.Close()
+
+	result := make(annotations)
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		i := strings.Index(line, ": ")
+		if i < 0 {
+			return nil, xerrors.Errorf("%s:%d: annotation must be 'path: description'", filename, n)
+		}
+		result[normalizeAnnotationPath(line[:i])] = strings.TrimSpace(line[i+2:])
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, xerrors.Errorf("failed to read annotations: %w", err)
+	}
+	return result, nil
+}
+
+func normalizeAnnotationPath(path string) string {
+	path = strings.TrimSpace(path)
+	path = strings.TrimPrefix(path, "./")
+	if path != "/" {
+		path = strings.TrimSuffix(path, "/")
+	}
+	if path == "" {
+		return "."
+	}
+	return path
+}
+
+// lookup returns the description of f.
+func (a annotations) lookup(f FileInfo) (string, bool) {
+	d, ok := a[relativePath(f)]
+	return d, ok
+}
@@ -0,0 +1,75 @@
+package gtree
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadAnnotations(t *testing.T) {
+	f, err := ioutil.TempFile("", ".gtree-annotations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+
+	content := `# comment
+.: the root
+./cmd/: commands
+
+internal/: private packages: not exported
+`
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	got, err := loadAnnotations(f.Name())
+	if err != nil {
+		t.Fatalf("loadAnnotations() returns error: %v", err)
+	}
+
+	expect := annotations{
+		".":        "the root",
+		"cmd":      "commands",
+		"internal": "private packages: not exported",
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("loadAnnotations() expected %v, got %v", expect, got)
+	}
+}
+
+func TestLoadAnnotations_JSONAndYAML(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gtree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"annotations.json": `{"./cmd/": "commands", "internal": "private packages"}`,
+		"annotations.yaml": "./cmd/: commands\ninternal: private packages\n",
+	}
+
+	expect := annotations{
+		"cmd":      "commands",
+		"internal": "private packages",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := loadAnnotations(path)
+		if err != nil {
+			t.Fatalf("loadAnnotations(%s) returns error: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, expect) {
+			t.Errorf("loadAnnotations(%s) expected %v, got %v", name, expect, got)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package gtree
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// walkArchive walks the entries of the zip, tar or gzipped tar archive.
+func walkArchive(root string, opts *ListSearchOptions) (<-chan FileInfo, error) {
+	tree, err := readArchive(root)
+	if err != nil {
+		return nil, err
+	}
+	return NodeTree(tree, opts), nil
+}
+
+// readArchive returns the tree of the entries in the archive.
+// The format is decided by the extension.
+func readArchive(filename string) (*Node, error) {
+	name := strings.ToLower(filename)
+	switch {
+	case strings.HasSuffix(name, ".zip"), strings.HasSuffix(name, ".jar"):
+		return readZip(filename)
+	case strings.HasSuffix(name, ".tar"):
+		return readTar(filename, false)
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return readTar(filename, true)
+	}
+	return nil, xerrors.Errorf("unknown archive format: %s, must be .zip, .tar, .tar.gz or .tgz", filename)
+}
+
+func readZip(filename string) (*Node, error) {
+	r, err := zip.OpenReader(filename)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to open archive: %w", err)
+	}
+	defer r.Close()
+
+	b := newPathTree(filename)
+	for _, f := range r.File {
+		info := f.FileInfo()
+		b.add(f.Name, info.IsDir()).info = info
+	}
+	return b.tree(), nil
+}
+
+func readTar(filename string, gzipped bool) (*Node, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to open archive: %w", err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if gzipped {
+		gr, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to open archive: %w", err)
+		}
+		defer gr.Close()
+		r = gr
+	}
+
+	b := newPathTree(filename)
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, xerrors.Errorf("failed to read archive: %w", err)
+		}
+
+		// Skip the global headers such as pax_global_header.
+		if h.Typeflag == tar.TypeXGlobalHeader {
+			continue
+		}
+
+		info := h.FileInfo()
+		b.add(h.Name, info.IsDir()).info = info
+	}
+	return b.tree(), nil
+}
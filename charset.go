@@ -0,0 +1,59 @@
+package gtree
+
+import "strings"
+
+// charset is a set of glyphs to draw lines of tree.
+type charset struct {
+	branch     string
+	last       string
+	vertical   string
+	horizontal string
+}
+
+var charsets = map[string]charset{
+	"ascii":   {branch: "|", last: "`", vertical: "|", horizontal: "-"},
+	"utf8":    {branch: "├", last: "└", vertical: "│", horizontal: "─"},
+	"rounded": {branch: "├", last: "╰", vertical: "│", horizontal: "─"},
+	"heavy":   {branch: "┣", last: "┗", vertical: "┃", horizontal: "━"},
+	"double":  {branch: "╠", last: "╚", vertical: "║", horizontal: "═"},
+}
+
+const (
+	defaultCharset = "utf8"
+	defaultIndent  = 4
+)
+
+// treeLines is the prefixes of tree lines drawn by a charset.
+type treeLines struct {
+	// branch is the prefix of a file followed by siblings such as '├── '.
+	branch string
+
+	// last is the prefix of the last file of siblings such as '└── '.
+	last string
+
+	// vertical is the prefix of children whose parent is followed by siblings such as '│   '.
+	vertical string
+
+	// blank is the prefix of children whose parent is the last such as '    '.
+	blank string
+}
+
+// newTreeLines returns the prefixes which have indent width.
+func newTreeLines(name string, indent int) treeLines {
+	c, ok := charsets[name]
+	if !ok {
+		c = charsets[defaultCharset]
+	}
+
+	if indent < 2 {
+		indent = defaultIndent
+	}
+
+	horizontal := strings.Repeat(c.horizontal, indent-2) + " "
+	return treeLines{
+		branch:   c.branch + horizontal,
+		last:     c.last + horizontal,
+		vertical: c.vertical + strings.Repeat(" ", indent-1),
+		blank:    strings.Repeat(" ", indent),
+	}
+}
@@ -0,0 +1,132 @@
+package gtree
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/gookit/color"
+)
+
+// FileKind is the kind of file decided from its mode and content.
+type FileKind int
+
+const (
+	// kindText is a text file, or the file which isn't classified.
+	kindText FileKind = iota
+	kindBinary
+	kindExecutable
+	kindGenerated
+)
+
+var (
+	binaryIcon     = Icon{Icon: "", Color: color.FgGray}
+	executableIcon = Icon{Icon: "", Color: color.FgLightGreen}
+	generatedIcon  = Icon{Icon: "", Color: color.FgGray}
+
+	executableColor = color.New(color.FgLightGreen)
+	generatedColor  = color.New(color.FgGray)
+)
+
+// generatedHeader matches the comment which marks generated files such as
+// '// Code generated by stringer; DO NOT EDIT.'
+var generatedHeader = regexp.MustCompile(`(?m)^(//|#) Code generated .* DO NOT EDIT\.\r?$`)
+
+// sniffFile returns the head of the file.
+func sniffFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, sniffLength)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// isBinary returns true, when head of the file contains null bytes or isn't detected as text.
+// It's used for both the kinds of files and '--lines' option, so that they agree on binary files.
+func isBinary(head []byte) bool {
+	if len(head) == 0 {
+		return false
+	}
+
+	if bytes.IndexByte(head, 0) >= 0 {
+		return true
+	}
+	return !strings.HasPrefix(http.DetectContentType(head), "text/")
+}
+
+// isGenerated returns true, when head of the file has the header of generated files.
+func isGenerated(head []byte) bool {
+	return generatedHeader.Match(head)
+}
+
+// classifyFile returns the kind of the file at path.
+// Generated files are decided by the content before the mode bits, so that generated scripts
+// are hidden by '--hide-generated' option. Then executables are decided by the mode bits.
+func classifyFile(path string, info os.FileInfo) (FileKind, error) {
+	mode := info.Mode()
+	if !mode.IsRegular() {
+		return kindText, nil
+	}
+
+	head, err := sniffFile(path)
+	if err != nil {
+		return kindText, err
+	}
+
+	switch {
+	case isGenerated(head):
+		return kindGenerated, nil
+	case mode&0111 != 0:
+		return kindExecutable, nil
+	case isBinary(head):
+		return kindBinary, nil
+	}
+	return kindText, nil
+}
+
+// ClassifyFiles classifies every regular file received from in
+// with a pool of workers, and sends the files to out in the received order.
+// It closes out when in is closed.
+func ClassifyFiles(in <-chan FileInfo, out chan<- FileInfo) {
+	processFiles(in, out, func(f FileInfo) {
+		info, ok := osFileInfo(f)
+		if !ok {
+			return
+		}
+
+		kind, err := classifyFile(f.Path(), info)
+		if err != nil {
+			f.SetError(err)
+		} else {
+			f.SetKind(kind)
+		}
+	})
+}
+
+// hideGenerated removes generated files in dir by '--hide-generated' option.
+func hideGenerated(dir string, files []os.FileInfo, opts *ListSearchOptions) []os.FileInfo {
+	if !opts.IsHideGenerated() {
+		return files
+	}
+
+	result := make([]os.FileInfo, 0, len(files))
+	for _, f := range files {
+		if !f.IsDir() {
+			if kind, err := classifyFile(dir+"/"+f.Name(), f); err == nil && kind == kindGenerated {
+				continue
+			}
+		}
+		result = append(result, f)
+	}
+	return result
+}
@@ -0,0 +1,89 @@
+package gtree
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClassifyFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gtree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := map[string]struct {
+		content string
+		mode    os.FileMode
+		kind    FileKind
+	}{
+		"text":           {"package main\n", 0644, kindText},
+		"empty":          {"", 0644, kindText},
+		"binary":         {"\x00\x01\x02\x03", 0644, kindBinary},
+		"image":          {"\x89PNG\r\n\x1a\n", 0644, kindBinary},
+		"executable":     {"#!/bin/sh\n", 0755, kindExecutable},
+		"binary exec":    {"\x7fELF\x02\x01\x01\x00", 0755, kindExecutable},
+		"generated exec": {"#!/bin/sh\n# Code generated by gen.sh. DO NOT EDIT.\n", 0755, kindGenerated},
+		"generated":      {"// Code generated by stringer; DO NOT EDIT.\n\npackage main\n", 0644, kindGenerated},
+		"generated #":    {"# Code generated by protoc. DO NOT EDIT.\n", 0644, kindGenerated},
+		"not generated":  {"package main\n\n// Code generated by hand.\n", 0644, kindText},
+	}
+
+	for key, tt := range tests {
+		t.Run(key, func(t *testing.T) {
+			path := filepath.Join(dir, key)
+			if err := ioutil.WriteFile(path, []byte(tt.content), tt.mode); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chmod(path, tt.mode); err != nil {
+				t.Fatal(err)
+			}
+
+			info, err := os.Lstat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			kind, err := classifyFile(path, info)
+			if err != nil {
+				t.Fatalf("classifyFile() unexpected error: %v", err)
+			}
+			if kind != tt.kind {
+				t.Errorf("classifyFile() expected %d, got %d", tt.kind, kind)
+			}
+		})
+	}
+}
+
+func TestHideGenerated(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gtree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for name, content := range map[string]string{
+		"a.go":      "package a\n",
+		"a_gen.go":  "// Code generated by x. DO NOT EDIT.\n\npackage a\n",
+		"README.md": "# a\n",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := hideGenerated(dir, infos, &ListSearchOptions{HideGenerated: []bool{true}})
+	if len(got) != 2 || got[0].Name() != "README.md" || got[1].Name() != "a.go" {
+		t.Errorf("hideGenerated() expected [README.md a.go], got %v", got)
+	}
+
+	if got := hideGenerated(dir, infos, &ListSearchOptions{}); len(got) != 3 {
+		t.Errorf("hideGenerated() without the option expected 3 files, got %d", len(got))
+	}
+}
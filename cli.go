@@ -1,4 +1,4 @@
-package main
+package gtree
 
 import (
 	"bufio"
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/jessevdk/go-flags"
 	"golang.org/x/xerrors"
@@ -30,7 +31,43 @@ type ListSearchOptions struct {
 
 	IgnorePatterns []string `short:"I" description:"Do not list files that match the given pattern."`
 
+	Patterns []string `short:"P" description:"List only those files that match the wild-card pattern."`
+
 	Level *int `short:"L" long:"level" description:"Descend only level directories deep."`
+
+	MinSize string `long:"min-size" description:"List files which are larger than or equal to size (e.g. 10M)."`
+
+	MaxSize string `long:"max-size" description:"List files which are smaller than or equal to size (e.g. 1k)."`
+
+	Newer string `long:"newer" description:"List files modified in the last age (e.g. 2d) or after the date (e.g. 2006-01-02)."`
+
+	Older string `long:"older" description:"List files modified before the age (e.g. 2d) or the date (e.g. 2006-01-02)."`
+
+	Type string `long:"type" description:"List files of the type: f(file), d(directory), l(symlink), x(executable), p(pipe), s(socket). Multiple types are separated by comma. Directories are always listed to show the tree, so use with '--prune' to list only matched directories and the ancestors of matched files."`
+
+	Empty []bool `long:"empty" description:"List empty files only. Directories are always listed to show the tree, so use with '--prune' to list only empty directories and the ancestors of empty files."`
+
+	FileLimit int `long:"filelimit" description:"Do not descend directories which have more than the number of entries."`
+
+	Head int `long:"head" description:"List only the first number of entries in each directory."`
+
+	Prune []bool `long:"prune" description:"Do not list directories which have no listed descendants. Descendants beyond '-L' are also checked."`
+
+	AnnotatedOnly []bool `long:"annotated-only" description:"List only files which have descriptions in the annotation file and their ancestors."`
+
+	Source string `long:"source" default:"disk" description:"Source of trees: disk, paths (a file which lists paths, '-' for stdin), archive (zip or tar) or data."`
+
+	Data []bool `long:"data" description:"Read JSON, YAML or TOML documents instead of directories, and list objects and arrays as directories. This is the same as '--source data'."`
+
+	HideGenerated []bool `long:"hide-generated" description:"Do not list generated files which have the header such as '// Code generated ... DO NOT EDIT.'"`
+
+	minSize *int64
+	maxSize *int64
+	newer   time.Time
+	older   time.Time
+	types   string
+
+	annotations annotations
 }
 
 // IsAll returns true, if user specify '-a' or '-all' option.
@@ -43,13 +80,102 @@ func (l *ListSearchOptions) IsOnlyDirectry() bool {
 	return len(l.OnlyDirectory) != 0
 }
 
+// IsEmpty returns true, if user specify '--empty' option.
+func (l *ListSearchOptions) IsEmpty() bool {
+	return len(l.Empty) != 0
+}
+
+// IsPrune returns true, if user specify '--prune' option.
+func (l *ListSearchOptions) IsPrune() bool {
+	return len(l.Prune) != 0
+}
+
+// IsData returns true, if user specify '--data' option.
+func (l *ListSearchOptions) IsData() bool {
+	return len(l.Data) != 0
+}
+
+// IsHideGenerated returns true, if user specify '--hide-generated' option.
+func (l *ListSearchOptions) IsHideGenerated() bool {
+	return len(l.HideGenerated) != 0
+}
+
+// IsAnnotatedOnly returns true, if user specify '--annotated-only' option.
+func (l *ListSearchOptions) IsAnnotatedOnly() bool {
+	return len(l.AnnotatedOnly) != 0
+}
+
 // ListDisplayOptions is options which use when display file tree.
 type ListDisplayOptions struct {
 	FullPath []bool `short:"f" description:"Print the full path prefix for each file."`
 
 	Output string `short:"o" description:"Output to file instead of stdout."`
 
+	Force []bool `long:"force" description:"Overwrite the output file without asking."`
+
+	NoClobber []bool `long:"no-clobber" description:"Do not overwrite the output file."`
+
 	NoIcons []bool `short:"n" description:"Do not show the icon of files and directories"`
+
+	QuestionMark []bool `short:"q" description:"Print non-printable characters in file names as '?'. By default, they are escaped such as '\\n' and '\\x1b'."`
+
+	Literal []bool `short:"N" description:"Print non-printable characters in file names as is."`
+
+	Quote []bool `short:"Q" long:"quote" description:"Quote file names with double quotes, and escape double quotes and backslashes in them."`
+
+	Color string `long:"color" choice:"auto" choice:"always" choice:"never" default:"auto" description:"Color the output. In auto mode, colors are used for terminals unless NO_COLOR is set, or CLICOLOR_FORCE is set."`
+
+	Icons string `long:"icons" choice:"auto" choice:"always" choice:"never" default:"auto" description:"Show the icons. In auto mode, icons are shown for terminals."`
+
+	Hash string `long:"hash" choice:"sha256" choice:"md5" choice:"xxhash" description:"Print the content digest of each file."`
+
+	FullHash []bool `long:"full-hash" description:"Print the whole digest instead of the truncated one."`
+
+	Dup []bool `long:"dup" description:"Highlight files whose content duplicates another file."`
+
+	Classify []bool `long:"classify" description:"Mark executable, binary and generated files with distinct icons and colors."`
+
+	Lines []bool `long:"lines" description:"Print the number of lines of each file, and the total of each directory. Binary files are skipped."`
+
+	Sort string `long:"sort" choice:"name" choice:"lines" default:"name" description:"Sort siblings by the name, or by the number of lines in descending order."`
+
+	IconWidth int `long:"icon-width" choice:"1" choice:"2" default:"1" description:"Width of icons on the terminal, which depends on the font. This is used to align annotations."`
+
+	Charset string `long:"charset" choice:"ascii" choice:"utf8" choice:"rounded" choice:"heavy" choice:"double" default:"utf8" description:"Characters to draw tree lines."`
+
+	Indent int `long:"indent" default:"4" description:"Width of indentation for each level."`
+
+	Format string `long:"format" default:"text" description:"Output format: text, markdown, dot (Graphviz), mermaid, ndjson (a JSON object per line), csv or tsv."`
+
+	Columns string `long:"columns" description:"Comma separated fields of csv and tsv formats: path, name, depth, type, size, mode, owner, mtime, hash, lines and ext. By default, path, name, depth, type, size, mode and mtime."`
+
+	GraphDirection string `long:"graph-direction" choice:"TB" choice:"LR" choice:"BT" choice:"RL" default:"TB" description:"Direction of the dot and mermaid diagrams such as top to bottom and left to right."`
+
+	GraphDirs string `long:"graph-dirs" choice:"node" choice:"cluster" default:"node" description:"Draw directories of the dot and mermaid diagrams as the parent nodes of their children, or the clusters which contain them."`
+
+	GraphHints []bool `long:"graph-hints" description:"Put the icons into labels, and fill nodes with the colors of the icons in the dot and mermaid diagrams."`
+
+	MarkdownStyle string `long:"markdown-style" choice:"code" choice:"list" default:"code" description:"Write markdown as a fenced code block or a nested bullet list."`
+
+	MarkdownLinks []bool `long:"markdown-links" description:"Link each entry of the markdown bullet list to the file."`
+
+	Annotate string `long:"annotate" description:"Read descriptions of files from the YAML or JSON file which maps paths to descriptions."`
+
+	Combine []bool `long:"combine" description:"List multiple directories as children of their common parent. Overlapping directories are listed once."`
+
+	Report []bool `long:"report" description:"Print the number of directories and files at the end. Overlapping directories are listed once. This is for text and markdown formats."`
+
+	Stats string `long:"stats" optional:"yes" optional-value:"text" choice:"text" choice:"json" choice:"csv" description:"Print file counts, sizes and lines grouped by the file type at the end. This is for text and markdown formats. json and csv print only the statistics without the trees."`
+
+	StatsBy string `long:"stats-by" choice:"type" choice:"language" default:"type" description:"Group the statistics by the file type or the language."`
+
+	Watch []bool `long:"watch" description:"Watch the tree, and redraw it on changes."`
+
+	Interactive []bool `short:"i" long:"interactive" description:"Explore the tree in the full-screen terminal UI, and print the path selected by 'o' on exit."`
+
+	annotations annotations
+	noIcon      bool
+	columns     []column
 }
 
 // IsFullPath returns true, if user specify '-f' option.
@@ -57,9 +183,87 @@ func (l *ListDisplayOptions) IsFullPath() bool {
 	return len(l.FullPath) != 0
 }
 
-// NoIcon returns true, if user specify '-n' option.
+// IsForce returns true, if user specify '--force' option.
+func (l *ListDisplayOptions) IsForce() bool {
+	return len(l.Force) != 0
+}
+
+// IsNoClobber returns true, if user specify '--no-clobber' option.
+func (l *ListDisplayOptions) IsNoClobber() bool {
+	return len(l.NoClobber) != 0
+}
+
+// NoIcon returns true, if user specify '-n' option or icons are disabled for the output.
 func (l *ListDisplayOptions) NoIcon() bool {
-	return len(l.NoIcons) != 0
+	return len(l.NoIcons) != 0 || l.noIcon
+}
+
+// IsQuestionMark returns true, if user specify '-q' option.
+func (l *ListDisplayOptions) IsQuestionMark() bool {
+	return len(l.QuestionMark) != 0
+}
+
+// IsLiteral returns true, if user specify '-N' option.
+func (l *ListDisplayOptions) IsLiteral() bool {
+	return len(l.Literal) != 0
+}
+
+// IsQuote returns true, if user specify '-Q' or '--quote' option.
+func (l *ListDisplayOptions) IsQuote() bool {
+	return len(l.Quote) != 0
+}
+
+// IsFullHash returns true, if user specify '--full-hash' option.
+func (l *ListDisplayOptions) IsFullHash() bool {
+	return len(l.FullHash) != 0
+}
+
+// digest returns the digest to display, which is truncated unless user specify '--full-hash' option.
+func (l *ListDisplayOptions) digest(hash string) string {
+	if !l.IsFullHash() && len(hash) > shortHashLength {
+		return hash[:shortHashLength]
+	}
+	return hash
+}
+
+// IsDup returns true, if user specify '--dup' option.
+func (l *ListDisplayOptions) IsDup() bool {
+	return len(l.Dup) != 0
+}
+
+// IsClassify returns true, if user specify '--classify' option.
+func (l *ListDisplayOptions) IsClassify() bool {
+	return len(l.Classify) != 0
+}
+
+// IsLines returns true, if user specify '--lines' option.
+func (l *ListDisplayOptions) IsLines() bool {
+	return len(l.Lines) != 0
+}
+
+// IsMarkdownLinks returns true, if user specify '--markdown-links' option.
+func (l *ListDisplayOptions) IsMarkdownLinks() bool {
+	return len(l.MarkdownLinks) != 0
+}
+
+// IsCombine returns true, if user specify '--combine' option.
+func (l *ListDisplayOptions) IsCombine() bool {
+	return len(l.Combine) != 0
+}
+
+// IsReport returns true, if user specify '--report' option.
+func (l *ListDisplayOptions) IsReport() bool {
+	return len(l.Report) != 0
+}
+
+// IsWatch returns true, if user specify '--watch' option.
+func (l *ListDisplayOptions) IsWatch() bool {
+	return len(l.Watch) != 0
+}
+
+// IsInteractive returns true, if user specify '-i' or '--interactive' option.
+func (l *ListDisplayOptions) IsInteractive() bool {
+	return len(l.Interactive) != 0
 }
 
 type MiscellaneousOptions struct {
@@ -70,11 +274,17 @@ type MiscellaneousOptions struct {
 type Options struct {
 	ListOptions          *ListOptions          `group:"List Options"`
 	MiscellaneousOptions *MiscellaneousOptions `group:"Miscellaneous Options"`
+
+	GoCommand *GoCommand `command:"go" description:"Show the package tree of the Go module with package names and doc summaries."`
+
+	ImportsCommand *ImportsCommand `command:"imports" description:"Show the transitive imports of the Go package as a tree."`
 }
 
 func newOptionsParser(opts *Options) *flags.Parser {
 	opts.ListOptions = &ListOptions{}
 	opts.MiscellaneousOptions = &MiscellaneousOptions{}
+	opts.GoCommand = &GoCommand{}
+	opts.ImportsCommand = &ImportsCommand{}
 
 	opts.MiscellaneousOptions.Version = func() {
 		fmt.Println("gtree v0.2")
@@ -83,13 +293,21 @@ func newOptionsParser(opts *Options) *flags.Parser {
 
 	parser := flags.NewParser(opts, flags.Default)
 	parser.Name = "gtree"
-	parser.Usage = "[-adfn] [--version] [-I pattern] [-o filename] [-L level] [--help] [--] [<directory list>]"
+	parser.Usage = "[OPTIONS] [--] [<directory list>]"
+	parser.LongDescription = "Directories named go or imports are taken as the commands. List them as paths such as './go', or after '--' such as 'gtree -- go'."
+	parser.SubcommandsOptional = true
 	return parser
 }
 
-// run is to search files, and display these files.
+var (
+	statusOK  = 0
+	statusErr = 1
+)
+
+// Run is to search files, and display these files by the command line arguments.
 // Search and Display communicate through channel.
-func run(ctx context.Context) int {
+// It returns the exit status.
+func Run(ctx context.Context) int {
 	var opts Options
 	parser := newOptionsParser(&opts)
 
@@ -98,90 +316,322 @@ func run(ctx context.Context) int {
 		return statusErr
 	}
 
+	if err := validateOptions(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", parser.Name, err)
+		return statusErr
+	}
+
 	if len(directories) == 0 {
-		directories = append(directories, ".")
+		switch opts.ListOptions.ListSearchOptions.Source {
+		case "", defaultSource:
+			directories = append(directories, ".")
+		case "paths":
+			directories = append(directories, "-")
+		default:
+			fmt.Fprintf(os.Stderr, "%s: %s source requires roots\n", parser.Name, opts.ListOptions.ListSearchOptions.Source)
+			return statusErr
+		}
+	}
+
+	displayOptions := opts.ListOptions.ListDisplayOptions
+	show := showTrees
+	if parser.Active != nil {
+		if displayOptions.IsInteractive() || displayOptions.IsWatch() {
+			fmt.Fprintf(os.Stderr, "%s: %s command can't be used with interactive or watch mode\n", parser.Name, parser.Active.Name)
+			return statusErr
+		}
+
+		if searchOptions := opts.ListOptions.ListSearchOptions; searchOptions.hasPredicate() || searchOptions.IsHideGenerated() {
+			fmt.Fprintf(os.Stderr, "%s: %s command can't be used with size, time, type, empty or hide generated options\n", parser.Name, parser.Active.Name)
+			return statusErr
+		}
+
+		switch parser.Active.Name {
+		case "go":
+			show = showGoTrees
+		case "imports":
+			show = showImportTrees
+		}
 	}
 
-	for _, d := range directories {
-		err = showTree(d, opts)
+	switch {
+	case displayOptions.IsInteractive():
+		applyOutputPolicy(displayOptions, true)
+	case displayOptions.Output != "":
+		applyOutputPolicy(displayOptions, false)
+	default:
+		applyOutputPolicy(displayOptions, isTerminal(os.Stdout))
+	}
+
+	if opts.ListOptions.ListDisplayOptions.IsInteractive() {
+		if len(directories) > 1 {
+			fmt.Fprintf(os.Stderr, "%s: interactive mode accepts only one directory\n", parser.Name)
+			return statusErr
+		}
+
+		err = runInteractive(directories[0], opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", parser.Name, err)
+			return statusErr
+		}
+		return statusOK
+	}
+
+	if opts.ListOptions.ListDisplayOptions.IsWatch() {
+		err = watchTree(ctx, directories, opts)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "%s: %v\n", parser.Name, err)
 			return statusErr
 		}
+		return statusOK
+	}
+
+	out, finishOutput, err := openOutput(displayOptions, isTerminal(os.Stdin))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", parser.Name, err)
+		return statusErr
+	}
+
+	err = show(out, directories, opts)
+	if finishErr := finishOutput(err == nil); err == nil {
+		err = finishErr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", parser.Name, err)
+		return statusErr
 	}
 
 	return statusOK
 }
 
-func showTree(root string, opts Options) error {
+// validateOptions checks the option values, and parses the predicates.
+func validateOptions(opts Options) error {
 	if opts.ListOptions.ListSearchOptions.Level != nil && *opts.ListOptions.ListSearchOptions.Level <= 0 {
 		return fmt.Errorf("Invalid level, must be greater than 0.")
 	}
 
+	if opts.ListOptions.ListDisplayOptions.Indent < 2 {
+		return fmt.Errorf("Invalid indent, must be greater than 1.")
+	}
+
+	if opts.ListOptions.ListSearchOptions.FileLimit < 0 {
+		return fmt.Errorf("Invalid filelimit, must be greater than 0.")
+	}
+
+	if opts.ListOptions.ListSearchOptions.Head < 0 {
+		return fmt.Errorf("Invalid head, must be greater than 0.")
+	}
+
+	if displayOptions := opts.ListOptions.ListDisplayOptions; displayOptions.Columns != "" {
+		columns, err := parseColumns(displayOptions.Columns)
+		if err != nil {
+			return err
+		}
+		displayOptions.columns = columns
+	} else {
+		displayOptions.columns, _ = parseColumns(defaultColumns)
+	}
+
+	if displayOptions := opts.ListOptions.ListDisplayOptions; (displayOptions.IsDup() || displayOptions.hasColumn("hash")) && displayOptions.Hash == "" {
+		displayOptions.Hash = "sha256"
+	}
+
+	if displayOptions := opts.ListOptions.ListDisplayOptions; displayOptions.hasColumn("lines") && !displayOptions.IsLines() {
+		displayOptions.Lines = []bool{true}
+	}
+
+	if displayOptions := opts.ListOptions.ListDisplayOptions; displayOptions.Sort == "lines" && !displayOptions.IsLines() {
+		displayOptions.Lines = []bool{true}
+	}
+
+	if annotate := opts.ListOptions.ListDisplayOptions.Annotate; annotate != "" {
+		a, err := loadAnnotations(annotate)
+		if err != nil {
+			return err
+		}
+		opts.ListOptions.ListDisplayOptions.annotations = a
+		opts.ListOptions.ListSearchOptions.annotations = a
+	} else if opts.ListOptions.ListSearchOptions.IsAnnotatedOnly() {
+		return fmt.Errorf("Annotated only option requires annotation file.")
+	}
+
+	if opts.ListOptions.ListDisplayOptions.IsForce() && opts.ListOptions.ListDisplayOptions.IsNoClobber() {
+		return fmt.Errorf("Force and no clobber options can't be used together.")
+	}
+
+	if opts.ListOptions.ListDisplayOptions.IsQuestionMark() && opts.ListOptions.ListDisplayOptions.IsLiteral() {
+		return fmt.Errorf("Question mark and literal options can't be used together.")
+	}
+
+	if opts.ListOptions.ListDisplayOptions.IsWatch() && opts.ListOptions.ListDisplayOptions.Output != "" {
+		return fmt.Errorf("Watch mode can't output to file.")
+	}
+
+	if searchOptions := opts.ListOptions.ListSearchOptions; searchOptions.IsData() {
+		if !isDefaultSource(searchOptions.Source) && searchOptions.Source != "data" {
+			return fmt.Errorf("Data option can't be used with %s source.", searchOptions.Source)
+		}
+		searchOptions.Source = "data"
+	}
+
+	if _, ok := formatterOf(opts.ListOptions.ListDisplayOptions.Format); !ok {
+		return fmt.Errorf("Invalid format, must be %s.", strings.Join(formatNames(), ", "))
+	}
+
+	if displayOptions := opts.ListOptions.ListDisplayOptions; !isTextFormat(displayOptions.Format) && (displayOptions.IsReport() || displayOptions.Stats != "") {
+		return fmt.Errorf("Report and stats options can be used only with text and markdown formats.")
+	}
+
+	if displayOptions := opts.ListOptions.ListDisplayOptions; isStatsDocument(displayOptions) && displayOptions.IsReport() {
+		return fmt.Errorf("Report option can't be used with json and csv stats, which print only the statistics.")
+	}
+
+	if _, ok := walkerOf(opts.ListOptions.ListSearchOptions.Source); !ok {
+		return fmt.Errorf("Invalid source, must be %s.", strings.Join(sourceNames(), ", "))
+	}
+
+	if displayOptions := opts.ListOptions.ListDisplayOptions; !isDefaultSource(opts.ListOptions.ListSearchOptions.Source) && (displayOptions.IsWatch() || displayOptions.IsInteractive() || displayOptions.IsCombine()) {
+		return fmt.Errorf("Interactive, watch and combine options can be used only with disk source.")
+	}
+
+	searchOptions := opts.ListOptions.ListSearchOptions
+	if err := searchOptions.parsePredicates(time.Now()); err != nil {
+		return err
+	}
+
+	if searchOptions.IsHideGenerated() && !isDefaultSource(searchOptions.Source) {
+		return fmt.Errorf("Hide generated option can be used only with disk source.")
+	}
+
+	if searchOptions.hasPredicate() && !hasMetadata(searchOptions.Source) {
+		return fmt.Errorf("Size, time, type and empty options can be used only with disk and archive sources.")
+	}
+	return nil
+}
+
+// newRootFileInfo returns FileInfo of the tree's root.
+func newRootFileInfo(root string) (FileInfo, error) {
+	return newRootFileInfoUnder(root, nil, true)
+}
+
+// newRootFileInfoUnder returns FileInfo of the tree's root whose parent is the synthetic directory.
+func newRootFileInfoUnder(root string, parent FileInfo, isLast bool) (FileInfo, error) {
 	f, err := os.Stat(root)
 	if err != nil {
-		return xerrors.Errorf("failed to find root: %v", err)
+		return nil, xerrors.Errorf("failed to find root: %v", err)
 	}
 
 	base, _ := filepath.Split(root)
-	rootFile := NewFileInfoForBase(f, nil, base, true)
-	ch := make(chan FileInfo)
+	rootFile := NewFileInfoForBase(f, parent, base, isLast)
 
 	if !rootFile.IsDir() {
 		errRootIsNotDir := fmt.Errorf("%s is not dir", rootFile.Name())
 		rootFile.SetError(errRootIsNotDir)
 	}
+	return rootFile, nil
+}
+
+// walkTree starts searching files under rootFile, and returns the channel which receives them.
+func walkTree(rootFile FileInfo, opts Options) <-chan FileInfo {
+	ch := make(chan FileInfo)
 
 	// Search files.
 	go Dirwalk(rootFile, ch, opts.ListOptions.ListSearchOptions)
+	return processTree(ch, opts)
+}
 
-	// Display files.
-	var out io.Writer
+// processTree reads the contents of files received from ch concurrently by display options,
+// and returns the channel which receives the files in the same order.
+func processTree(ch <-chan FileInfo, opts Options) <-chan FileInfo {
+	// Hash files concurrently with the search.
+	if hash := opts.ListOptions.ListDisplayOptions.Hash; hash != "" {
+		hashed := make(chan FileInfo)
+		go HashFiles(ch, hashed, hash)
+		ch = hashed
+	}
 
-	if outputFile := opts.ListOptions.ListDisplayOptions.Output; outputFile != "" {
-		var err error
-		if err = checkOverWrite(outputFile); err != nil {
-			return xerrors.Errorf("denided overwrite: %w", err)
-		}
+	// Classify files concurrently with the search.
+	if opts.ListOptions.ListDisplayOptions.IsClassify() {
+		classified := make(chan FileInfo)
+		go ClassifyFiles(ch, classified)
+		ch = classified
+	}
 
-		out, err = os.Create(outputFile)
-		if err != nil {
-			return xerrors.Errorf("file create/open error: %w", err)
-		}
-		defer out.(*os.File).Close()
-	} else {
-		out = os.Stdout
+	// Count lines concurrently with the search.
+	if opts.ListOptions.ListDisplayOptions.IsLines() {
+		counted := make(chan FileInfo)
+		go CountLines(ch, counted)
+		ch = counted
+	}
+	return ch
+}
+
+// showTrees writes the trees of roots to out.
+func showTrees(out io.Writer, roots []string, opts Options) error {
+	displayOptions := opts.ListOptions.ListDisplayOptions
+	if displayOptions.IsCombine() || displayOptions.IsReport() {
+		roots = dedupRoots(roots)
 	}
 
 	w := bufio.NewWriter(out)
-	p := NewPrinter(opts.ListOptions.ListDisplayOptions)
+	// The trees written before an error are shown. The error of flushing is returned at the end.
+	defer w.Flush()
 
-	for file := range ch {
-		err := p.Write(w, file)
-		if err != nil {
+	tw, err := newTreeWriter(w, displayOptions, newReport(displayOptions))
+	if err != nil {
+		return err
+	}
+
+	if displayOptions.IsCombine() {
+		if err := showCombinedTree(tw, roots, opts); err != nil {
 			return err
 		}
+	} else {
+		for _, root := range roots {
+			if err := showTree(tw, root, opts); err != nil {
+				return err
+			}
+		}
 	}
-
-	w.Flush()
-	return nil
+	if err := tw.close(); err != nil {
+		return err
+	}
+	return flushOutput(w)
 }
 
-var errFileExist = fmt.Errorf("output file already exists")
+func showTree(tw *treeWriter, root string, opts Options) error {
+	walker, _ := walkerOf(opts.ListOptions.ListSearchOptions.Source)
+	ch, err := walker.Walk(root, opts.ListOptions.ListSearchOptions)
+	if err != nil {
+		return err
+	}
 
-func checkOverWrite(filename string) error {
-	var err error
-	if _, err = os.Stat(filename); !os.IsNotExist(err) {
-		fmt.Printf("Output file already exists. Are you sure to overwrite %s?[Y/n] ", filename)
+	return tw.writeTree(processTree(ch, opts), opts.ListOptions.ListDisplayOptions.annotations)
+}
 
-		var answer string
-		if _, err = fmt.Scan(&answer); err != nil {
-			return xerrors.Errorf("failed to scan answer: %v", err)
-		}
+// showCombinedTree writes roots as children of the synthetic top directory.
+func showCombinedTree(tw *treeWriter, roots []string, opts Options) error {
+	top := newVirtualDir(commonDir(roots))
 
-		if strings.ToLower(strings.TrimRight(answer, "\n")) != "y" {
-			return errFileExist
+	rootFiles := make([]FileInfo, 0, len(roots))
+	for i, root := range roots {
+		rootFile, err := newRootFileInfoUnder(root, top, i == len(roots)-1)
+		if err != nil {
+			return err
 		}
+		rootFiles = append(rootFiles, rootFile)
 	}
-	return nil
+
+	ch := make(chan FileInfo)
+	go func() {
+		ch <- top
+		for _, rootFile := range rootFiles {
+			for f := range walkTree(rootFile, opts) {
+				ch <- f
+			}
+		}
+		close(ch)
+	}()
+
+	return tw.writeTree(ch, opts.ListOptions.ListDisplayOptions.annotations)
 }
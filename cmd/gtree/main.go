@@ -3,14 +3,11 @@ package main
 import (
 	"context"
 	"os"
-)
 
-var (
-	statusOK  = 0
-	statusErr = 1
+	"github.com/kitagry/gtree"
 )
 
 func main() {
-	exitCode := run(context.Background())
+	exitCode := gtree.Run(context.Background())
 	os.Exit(exitCode)
 }
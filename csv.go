@@ -0,0 +1,201 @@
+package gtree
+
+import (
+	"archive/tar"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// defaultColumns is the columns of csv and tsv formats when '--columns' option isn't specified.
+const defaultColumns = "path,name,depth,type,size,mode,mtime"
+
+// column is a field of csv and tsv formats.
+type column struct {
+	name  string
+	value func(e *Entry, opt *ListDisplayOptions) string
+}
+
+// columns is the fields which are selected by '--columns' option.
+var columns = []column{
+	{"path", func(e *Entry, opt *ListDisplayOptions) string {
+		return e.Path()
+	}},
+	{"name", func(e *Entry, opt *ListDisplayOptions) string {
+		return labelOf(e.FileInfo, e.Name())
+	}},
+	{"depth", func(e *Entry, opt *ListDisplayOptions) string {
+		return strconv.Itoa(e.Depth)
+	}},
+	{"type", func(e *Entry, opt *ListDisplayOptions) string {
+		info, ok := statOf(e.FileInfo)
+		return typeName(e.FileInfo, info, ok)
+	}},
+	{"size", func(e *Entry, opt *ListDisplayOptions) string {
+		if info, ok := statOf(e.FileInfo); ok {
+			return strconv.FormatInt(info.Size(), 10)
+		}
+		return ""
+	}},
+	{"mode", func(e *Entry, opt *ListDisplayOptions) string {
+		if info, ok := statOf(e.FileInfo); ok {
+			return info.Mode().String()
+		}
+		return ""
+	}},
+	{"owner", func(e *Entry, opt *ListDisplayOptions) string {
+		if info, ok := statOf(e.FileInfo); ok {
+			owner, _ := ownerOf(info)
+			return owner
+		}
+		return ""
+	}},
+	{"mtime", func(e *Entry, opt *ListDisplayOptions) string {
+		if info, ok := statOf(e.FileInfo); ok {
+			return info.ModTime().Format(time.RFC3339)
+		}
+		return ""
+	}},
+	{"hash", func(e *Entry, opt *ListDisplayOptions) string {
+		return e.Hash()
+	}},
+	{"lines", func(e *Entry, opt *ListDisplayOptions) string {
+		if lines, ok := e.Lines(); ok {
+			return strconv.Itoa(lines)
+		}
+		return ""
+	}},
+	{"ext", func(e *Entry, opt *ListDisplayOptions) string {
+		if e.IsDir() {
+			return ""
+		}
+		return strings.TrimPrefix(filepath.Ext(e.Name()), ".")
+	}},
+}
+
+// parseColumns returns the columns of the comma separated names.
+func parseColumns(s string) ([]column, error) {
+	var result []column
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		c, ok := columnOf(name)
+		if !ok {
+			return nil, fmt.Errorf("Invalid column %s, must be %s.", name, strings.Join(columnNames(), ", "))
+		}
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+func columnOf(name string) (column, bool) {
+	for _, c := range columns {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return column{}, false
+}
+
+func columnNames() []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.name
+	}
+	return names
+}
+
+// hasColumn returns true, if the format is csv or tsv, and name is selected by '--columns' option.
+func (l *ListDisplayOptions) hasColumn(name string) bool {
+	if l.Format != "csv" && l.Format != "tsv" {
+		return false
+	}
+
+	for _, c := range l.columns {
+		if c.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ownerOf returns the user name of the owner of the file on the disk or the tar archive.
+func ownerOf(info os.FileInfo) (string, bool) {
+	if h, ok := info.Sys().(*tar.Header); ok {
+		if h.Uname != "" {
+			return h.Uname, true
+		}
+		return strconv.Itoa(h.Uid), true
+	}
+	return fileOwner(info)
+}
+
+// CSVFormatter writes each file as a record of CSV or TSV with the header row.
+type CSVFormatter struct {
+	opt   *ListDisplayOptions
+	comma rune
+	w     *csv.Writer
+}
+
+// NewCSVFormatter returns CSVFormatter pointer whose fields are separated by comma.
+func NewCSVFormatter(opt *ListDisplayOptions, comma rune) *CSVFormatter {
+	return &CSVFormatter{opt: opt, comma: comma}
+}
+
+// Begin writes the header row.
+func (c *CSVFormatter) Begin(w io.Writer) error {
+	c.w = csv.NewWriter(w)
+	c.w.Comma = c.comma
+
+	header := make([]string, len(c.opt.columns))
+	for i, col := range c.opt.columns {
+		header[i] = col.name
+	}
+	return c.write(header)
+}
+
+// EnterDir writes the record of the directory.
+func (c *CSVFormatter) EnterDir(w io.Writer, e *Entry) error {
+	return c.Entry(w, e)
+}
+
+// Entry writes the record of the file.
+// The placeholders of '--head' option are skipped, since they aren't files.
+func (c *CSVFormatter) Entry(w io.Writer, e *Entry) error {
+	if IsOmitted(e.FileInfo) {
+		return nil
+	}
+
+	record := make([]string, len(c.opt.columns))
+	for i, col := range c.opt.columns {
+		record[i] = col.value(e, c.opt)
+	}
+	return c.write(record)
+}
+
+// LeaveDir does nothing, since the records of descendants are already written.
+func (c *CSVFormatter) LeaveDir(w io.Writer, e *Entry) error {
+	return nil
+}
+
+// End flushes the records.
+func (c *CSVFormatter) End(w io.Writer, r *Report) error {
+	c.w.Flush()
+	if err := c.w.Error(); err != nil {
+		return xerrors.Errorf("failed to write: %w", err)
+	}
+	return nil
+}
+
+func (c *CSVFormatter) write(record []string) error {
+	if err := c.w.Write(record); err != nil {
+		return xerrors.Errorf("failed to write: %w", err)
+	}
+	return nil
+}
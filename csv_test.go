@@ -0,0 +1,145 @@
+package gtree
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCSVFormatter(t *testing.T) {
+	root := newDummyPrinterFileInfo("root", "root", "", "", true, true, nil, nil)
+	dir := newDummyPrinterFileInfo("a,b", "root/a,b", "", "", false, true, nil, root)
+	file := newDummyPrinterFileInfo("say \"hi\"\n.go", "root/a,b/say \"hi\"\n.go", "go", "", true, false, nil, dir)
+	tab := newDummyPrinterFileInfo("a\tb.txt", "root/a\tb.txt", "txt", "", true, false, nil, root)
+	files := []FileInfo{root, dir, file, tab}
+
+	tests := map[string]struct {
+		format  string
+		columns string
+		output  string
+	}{
+		"csv": {
+			format:  "csv",
+			columns: "path,depth,type,ext",
+			output: "path,depth,type,ext\n" +
+				"root,0,directory,\n" +
+				"\"root/a,b\",1,directory,\n" +
+				"\"root/a,b/say \"\"hi\"\"\n.go\",2,file,go\n" +
+				"root/a\tb.txt,1,file,txt\n",
+		},
+		"tsv": {
+			format:  "tsv",
+			columns: "name,size",
+			output: "name\tsize\n" +
+				"root\t\n" +
+				"a,b\t\n" +
+				"\"say \"\"hi\"\"\n.go\"\t\n" +
+				"\"a\tb.txt\"\t\n",
+		},
+	}
+
+	for key, tt := range tests {
+		t.Run(key, func(t *testing.T) {
+			columns, err := parseColumns(tt.columns)
+			if err != nil {
+				t.Fatal(err)
+			}
+			opt := &ListDisplayOptions{Format: tt.format, columns: columns}
+			f, _ := formatterOf(tt.format)
+			p := f(opt)
+
+			buffer := new(bytes.Buffer)
+			fw := newFormatWriter(p)
+			if err := p.Begin(buffer); err != nil {
+				t.Fatal(err)
+			}
+			for _, file := range files {
+				if err := fw.write(buffer, file); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := fw.close(buffer); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.End(buffer, &Report{}); err != nil {
+				t.Fatal(err)
+			}
+
+			if buffer.String() != tt.output {
+				t.Errorf("expected\n%q\nbut got\n%q", tt.output, buffer.String())
+			}
+		})
+	}
+}
+
+func TestShowTrees_CSV(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gtree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeTestFiles(t, dir, map[string]string{
+		"a/x.txt": "x",
+		"b/y.txt": "y",
+	})
+
+	opts := Options{ListOptions: &ListOptions{
+		ListSearchOptions:  &ListSearchOptions{},
+		ListDisplayOptions: &ListDisplayOptions{Format: "csv", Columns: "depth,name,hash", Indent: 4},
+	}}
+	if err := validateOptions(opts); err != nil {
+		t.Fatal(err)
+	}
+
+	buffer := new(bytes.Buffer)
+	roots := []string{filepath.Join(dir, "a"), filepath.Join(dir, "b")}
+	if err := showTrees(buffer, roots, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	// The header is written once, and digests aren't truncated.
+	expect := "depth,name,hash\n" +
+		"0," + roots[0] + ",\n" +
+		"1,x.txt,2d711642b726b04401627ca9fbac32f5c8530fb1903cc4db02258717921a4881\n" +
+		"0," + roots[1] + ",\n" +
+		"1,y.txt,a1fce4363854ff888cff4b8e7875d600c2682390412a8cf79b37d0b11148b0fa\n"
+	if buffer.String() != expect {
+		t.Errorf("expected\n%q\nbut got\n%q", expect, buffer.String())
+	}
+}
+
+func TestValidateOptions_Columns(t *testing.T) {
+	tests := map[string]struct {
+		format  string
+		columns string
+		hash    string
+		lines   bool
+		err     bool
+	}{
+		"default":      {"csv", "", "", false, false},
+		"hash":         {"csv", "path,hash", "sha256", false, false},
+		"lines":        {"tsv", "path, lines", "", true, false},
+		"hash in text": {"text", "path,hash", "", false, false},
+		"unknown":      {"csv", "path,color", "", false, true},
+	}
+
+	for key, tt := range tests {
+		t.Run(key, func(t *testing.T) {
+			display := &ListDisplayOptions{Format: tt.format, Columns: tt.columns, Indent: 4}
+			opts := Options{ListOptions: &ListOptions{ListSearchOptions: &ListSearchOptions{}, ListDisplayOptions: display}}
+			err := validateOptions(opts)
+			if (err != nil) != tt.err {
+				t.Fatalf("validateOptions() expected error %v, got %v", tt.err, err)
+			}
+			if tt.err {
+				return
+			}
+			if display.Hash != tt.hash || display.IsLines() != tt.lines {
+				t.Errorf("validateOptions() expected hash '%s' and lines %v, got '%s' and %v", tt.hash, tt.lines, display.Hash, display.IsLines())
+			}
+		})
+	}
+}
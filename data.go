@@ -0,0 +1,273 @@
+package gtree
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v2"
+)
+
+// dataField is a key and its value of an object in the document.
+type dataField struct {
+	key   string
+	value interface{}
+}
+
+// dataObject is an object in the document whose keys are in the order of the document.
+type dataObject []dataField
+
+// loadData reads the JSON, YAML or TOML document, and returns the tree of it.
+// Objects and arrays are directories, and scalars are leaves.
+func loadData(filename string) (*Node, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read data: %w", err)
+	}
+
+	var v interface{}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		v, err = decodeJSON(b)
+	case ".yaml", ".yml":
+		v, err = decodeYAML(b)
+	case ".toml":
+		v, err = decodeTOML(b)
+	default:
+		return nil, xerrors.Errorf("unknown data format: %s, must be .json, .yaml, .yml or .toml", filename)
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("failed to parse %s: %w", filename, err)
+	}
+
+	return newDataNode(filename, v), nil
+}
+
+// newDataNode returns the node of the value v whose key is key.
+func newDataNode(key string, v interface{}) *Node {
+	switch v := v.(type) {
+	case dataObject:
+		n := NewNode(key, "", true)
+		for _, f := range v {
+			n.Add(newDataNode(f.key, f.value))
+		}
+		return n
+	case []interface{}:
+		n := NewNode(key, "", true)
+		for i, e := range v {
+			n.Add(newDataNode("["+strconv.Itoa(i)+"]", e))
+		}
+		return n
+	}
+
+	n := NewNode(key, "", false)
+	n.value = formatDataValue(v)
+	return n
+}
+
+// formatDataValue returns the scalar such as '"text"', '10', 'true' and 'null'.
+func formatDataValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return strconv.Quote(v)
+	case json.Number:
+		return v.String()
+	}
+	return fmt.Sprint(v)
+}
+
+// decodeJSON decodes the JSON document keeping the order of keys.
+func decodeJSON(b []byte) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+
+	v, err := decodeJSONValue(d)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := d.Token(); err != io.EOF {
+		return nil, xerrors.New("unexpected data after the top-level value")
+	}
+	return v, nil
+}
+
+func decodeJSONValue(d *json.Decoder) (interface{}, error) {
+	t, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t {
+	case json.Delim('{'):
+		obj := make(dataObject, 0)
+		for d.More() {
+			k, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			v, err := decodeJSONValue(d)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, dataField{key: k.(string), value: v})
+		}
+
+		// '}'
+		if _, err := d.Token(); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	case json.Delim('['):
+		arr := make([]interface{}, 0)
+		for d.More() {
+			v, err := decodeJSONValue(d)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+
+		// ']'
+		if _, err := d.Token(); err != nil {
+			return nil, err
+		}
+		return arr, nil
+	}
+	return t, nil
+}
+
+// yamlValue is a YAML value whose mappings keep the order of keys.
+type yamlValue struct {
+	v interface{}
+}
+
+func (y *yamlValue) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var m yaml.MapSlice
+	if err := unmarshal(&m); err == nil && m != nil {
+		y.v = m
+		return nil
+	}
+
+	var a []yamlValue
+	if err := unmarshal(&a); err == nil && a != nil {
+		y.v = a
+		return nil
+	}
+	return unmarshal(&y.v)
+}
+
+// decodeYAML decodes the YAML document keeping the order of keys.
+func decodeYAML(b []byte) (interface{}, error) {
+	var y yamlValue
+	if err := yaml.Unmarshal(b, &y); err != nil {
+		return nil, err
+	}
+	return fromYAML(y.v), nil
+}
+
+func fromYAML(v interface{}) interface{} {
+	switch v := v.(type) {
+	case yamlValue:
+		return fromYAML(v.v)
+	case yaml.MapSlice:
+		obj := make(dataObject, 0, len(v))
+		for _, item := range v {
+			obj = append(obj, dataField{key: fmt.Sprint(item.Key), value: fromYAML(item.Value)})
+		}
+		return obj
+	case map[interface{}]interface{}:
+		obj := make(dataObject, 0, len(v))
+		for k, e := range v {
+			obj = append(obj, dataField{key: fmt.Sprint(k), value: fromYAML(e)})
+		}
+		sort.Slice(obj, func(i, j int) bool { return obj[i].key < obj[j].key })
+		return obj
+	case []yamlValue:
+		arr := make([]interface{}, 0, len(v))
+		for _, e := range v {
+			arr = append(arr, fromYAML(e))
+		}
+		return arr
+	case []interface{}:
+		arr := make([]interface{}, 0, len(v))
+		for _, e := range v {
+			arr = append(arr, fromYAML(e))
+		}
+		return arr
+	}
+	return v
+}
+
+// decodeTOML decodes the TOML document keeping the order of keys.
+func decodeTOML(b []byte) (interface{}, error) {
+	var m map[string]interface{}
+	md, err := toml.Decode(string(b), &m)
+	if err != nil {
+		return nil, err
+	}
+
+	// The order of the keys which first appear in the document.
+	order := make(map[string]int)
+	for i, k := range md.Keys() {
+		if _, ok := order[k.String()]; !ok {
+			order[k.String()] = i
+		}
+	}
+	return fromTOML(m, "", order), nil
+}
+
+func fromTOML(v interface{}, path string, order map[string]int) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		obj := make(dataObject, 0, len(v))
+		for k, e := range v {
+			obj = append(obj, dataField{key: k, value: fromTOML(e, joinTOMLKey(path, k), order)})
+		}
+		sort.Slice(obj, func(i, j int) bool {
+			oi, iok := order[joinTOMLKey(path, obj[i].key)]
+			oj, jok := order[joinTOMLKey(path, obj[j].key)]
+			if iok != jok {
+				return iok
+			}
+			if oi != oj {
+				return oi < oj
+			}
+			return obj[i].key < obj[j].key
+		})
+		return obj
+	case []map[string]interface{}:
+		arr := make([]interface{}, 0, len(v))
+		for _, e := range v {
+			arr = append(arr, fromTOML(e, path, order))
+		}
+		return arr
+	case []interface{}:
+		arr := make([]interface{}, 0, len(v))
+		for _, e := range v {
+			arr = append(arr, fromTOML(e, path, order))
+		}
+		return arr
+	}
+	return v
+}
+
+// joinTOMLKey returns the key in the same form as toml.Key.String().
+func joinTOMLKey(path, key string) string {
+	k := toml.Key{key}.String()
+	if path == "" {
+		return k
+	}
+	return path + "." + k
+}
@@ -0,0 +1,129 @@
+package gtree
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadData(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gtree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeTestFiles(t, dir, map[string]string{
+		"a.json": `{"name": "gtree", "version": 1.5, "tags": ["a", "b"], "nested": {"z": null, "a": true}}`,
+		"a.yaml": "name: gtree\nversion: 1.5\ntags: [a, b]\nnested:\n  z: null\n  a: true\n",
+		"a.toml": "name = \"gtree\"\nversion = 1.5\ntags = [\"a\", \"b\"]\n\n[nested]\nz = \"\"\na = true\n",
+		"a.txt":  "",
+	})
+
+	level := 1
+	tests := map[string]struct {
+		file   string
+		opts   *ListSearchOptions
+		expect string
+	}{
+		"json": {
+			file:   "a.json",
+			opts:   &ListSearchOptions{},
+			expect: "├── name: \"gtree\"\n├── version: 1.5\n├── tags\n│   ├── [0]: \"a\"\n│   └── [1]: \"b\"\n└── nested\n    ├── z: null\n    └── a: true\n",
+		},
+		"yaml": {
+			file:   "a.yaml",
+			opts:   &ListSearchOptions{},
+			expect: "├── name: \"gtree\"\n├── version: 1.5\n├── tags\n│   ├── [0]: \"a\"\n│   └── [1]: \"b\"\n└── nested\n    ├── z: null\n    └── a: true\n",
+		},
+		"toml": {
+			file:   "a.toml",
+			opts:   &ListSearchOptions{},
+			expect: "├── name: \"gtree\"\n├── version: 1.5\n├── tags\n│   ├── [0]: \"a\"\n│   └── [1]: \"b\"\n└── nested\n    ├── z: \"\"\n    └── a: true\n",
+		},
+		"level": {
+			file:   "a.json",
+			opts:   &ListSearchOptions{Level: &level},
+			expect: "├── name: \"gtree\"\n├── version: 1.5\n├── tags\n└── nested\n",
+		},
+		"pattern on keys": {
+			file:   "a.json",
+			opts:   &ListSearchOptions{Patterns: []string{"[az]"}, Prune: []bool{true}},
+			expect: "└── nested\n    ├── z: null\n    └── a: true\n",
+		},
+	}
+
+	for key, tt := range tests {
+		t.Run(key, func(t *testing.T) {
+			path := filepath.Join(dir, tt.file)
+			tree, err := loadData(path)
+			if err != nil {
+				t.Fatalf("loadData() unexpected error: %v", err)
+			}
+
+			p := NewPrinter(&ListDisplayOptions{NoIcons: []bool{true}})
+			buffer := new(bytes.Buffer)
+			ch := make(chan FileInfo)
+			go nodeWalk(tree, ch, tt.opts)
+			for f := range ch {
+				if err := p.Write(buffer, f); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			expect := path + "\n" + tt.expect
+			if got := ansiEscape.ReplaceAllString(buffer.String(), ""); got != expect {
+				t.Errorf("loadData() expected '%s', got '%s'", expect, got)
+			}
+		})
+	}
+
+	if _, err := loadData(filepath.Join(dir, "a.txt")); err == nil {
+		t.Errorf("loadData() expected error for unknown format")
+	}
+}
+
+func TestLoadData_Path(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gtree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "a.json")
+	writeTestFiles(t, dir, map[string]string{"a.json": `{"nested": {"a": true}}`})
+
+	tree, err := loadData(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The value is shown only in the label, and the paths have only the keys.
+	scalar := tree.children[0].children[0]
+	if got := scalar.Path(); got != path+"/nested/a" {
+		t.Errorf("Path() expected '%s', got '%s'", path+"/nested/a", got)
+	}
+	if got := relativePath(scalar); got != "nested/a" {
+		t.Errorf("relativePath() expected 'nested/a', got '%s'", got)
+	}
+
+	p := NewPrinter(&ListDisplayOptions{NoIcons: []bool{true}, FullPath: []bool{true}})
+	buffer := new(bytes.Buffer)
+	if err := p.Write(buffer, scalar); err != nil {
+		t.Fatal(err)
+	}
+	if got, expect := ansiEscape.ReplaceAllString(buffer.String(), ""), " "+path+"/nested/a: true\n"; !strings.HasSuffix(got, expect) {
+		t.Errorf("printer.Write() with '--full-path' expected '%s', got '%s'", expect, got)
+	}
+}
+
+func TestDecodeJSON_Invalid(t *testing.T) {
+	for _, in := range []string{"", "{", `{"a": 1} {}`, "[1,"} {
+		if _, err := decodeJSON([]byte(in)); err == nil {
+			t.Errorf("decodeJSON(%q) expected error", in)
+		}
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package gtree
 
 import (
 	"os"
@@ -27,9 +27,6 @@ type FileInfo interface {
 	// When FileInfo doesn't have parent, return false.
 	Parent() (FileInfo, bool)
 
-	// ChildPrefix is prefix of children's prefix
-	ChildPrefix() string
-
 	// IsDir returns true, when FileInfo is directory
 	IsDir() bool
 
@@ -44,6 +41,27 @@ type FileInfo interface {
 
 	// Error return error
 	Error() error
+
+	// SetHash set content digest
+	SetHash(hash string)
+
+	// Hash returns content digest
+	// If the digest isn't computed, returns ""
+	Hash() string
+
+	// SetLines set the number of lines
+	SetLines(lines int)
+
+	// Lines returns the number of lines
+	// If the lines aren't counted such as binary files, returns false
+	Lines() (int, bool)
+
+	// SetKind set the kind decided from the content
+	SetKind(kind FileKind)
+
+	// Kind returns the kind decided from the content
+	// If the file isn't classified, returns kindText
+	Kind() FileKind
 }
 
 // NewFileInfo returns File when f is file. And, when f is folder, this returns Folder.
@@ -89,6 +107,11 @@ type baseFileInfo struct {
 	base   string
 	path   string
 	err    error
+	hash   string
+
+	lines   int
+	counted bool
+	kind    FileKind
 }
 
 func (f *baseFileInfo) Name() string {
@@ -97,7 +120,7 @@ func (f *baseFileInfo) Name() string {
 
 func (f *baseFileInfo) Path() string {
 	if f.path == "" {
-		if f.parent == nil {
+		if f.parent == nil || isVirtualDir(f.parent) {
 			f.path = f.Name()
 		} else {
 			f.path = f.parent.Path() + "/" + f.Name()
@@ -143,6 +166,35 @@ func (f *baseFileInfo) Error() error {
 	return f.err
 }
 
+func (f *baseFileInfo) SetHash(hash string) {
+	f.hash = hash
+}
+
+func (f *baseFileInfo) Hash() string {
+	return f.hash
+}
+
+func (f *baseFileInfo) SetLines(lines int) {
+	f.lines = lines
+	f.counted = true
+}
+
+func (f *baseFileInfo) Lines() (int, bool) {
+	return f.lines, f.counted
+}
+
+func (f *baseFileInfo) SetKind(kind FileKind) {
+	f.kind = kind
+}
+
+func (f *baseFileInfo) Kind() FileKind {
+	return f.kind
+}
+
+func (f *baseFileInfo) setLast(isLast bool) {
+	f.isLast = isLast
+}
+
 type file struct {
 	baseFileInfo
 }
@@ -156,12 +208,14 @@ func newFile(base baseFileInfo) FileInfo {
 }
 
 func (f *file) FileType() string {
-	n := strings.Split(f.Name(), ".")
-	return n[len(n)-1]
+	return fileTypeOf(f.Name())
 }
 
-func (f *file) ChildPrefix() string {
-	return ""
+// fileTypeOf returns the extension of the file name.
+// When the name has no extension, returns the name.
+func fileTypeOf(name string) string {
+	n := strings.Split(name, ".")
+	return n[len(n)-1]
 }
 
 func (f *file) IsSym() bool {
@@ -170,8 +224,6 @@ func (f *file) IsSym() bool {
 
 type folder struct {
 	baseFileInfo
-
-	childPrefix string
 }
 
 var _ FileInfo = (*folder)(nil)
@@ -186,14 +238,200 @@ func (f *folder) FileType() string {
 	return ""
 }
 
-func (f *folder) ChildPrefix() string {
+// osFileInfo returns os.FileInfo of f, when f is a file on the disk.
+func osFileInfo(f FileInfo) (os.FileInfo, bool) {
+	info, ok := f.(interface{ osFileInfo() os.FileInfo })
+	if !ok {
+		return nil, false
+	}
+	return info.osFileInfo(), true
+}
+
+func (f *baseFileInfo) osFileInfo() os.FileInfo {
+	return f.FileInfo
+}
+
+// relativePath returns the path from the tree's root.
+// The root itself is ".".
+func relativePath(f FileInfo) string {
 	p, ok := f.Parent()
-	if f.childPrefix == "" && ok {
-		if f.IsLast() {
-			f.childPrefix = p.ChildPrefix() + "    "
-		} else {
-			f.childPrefix = p.ChildPrefix() + "│   "
-		}
+	if !ok {
+		return "."
+	}
+
+	if pp := relativePath(p); pp != "." {
+		return pp + "/" + f.Name()
+	}
+	return f.Name()
+}
+
+// omitted is a placeholder for files which aren't listed by '--head' option.
+// This is displayed as the last child of parent.
+type omitted struct {
+	parent FileInfo
+	count  int
+	err    error
+}
+
+var _ FileInfo = (*omitted)(nil)
+
+func newOmitted(parent FileInfo, count int) FileInfo {
+	return &omitted{
+		parent: parent,
+		count:  count,
 	}
-	return f.childPrefix
+}
+
+// IsOmitted returns true, when f is the placeholder for omitted files by '--head' option.
+func IsOmitted(f FileInfo) bool {
+	_, ok := f.(*omitted)
+	return ok
+}
+
+// Name returns the number of omitted entries, which may be files and directories.
+func (o *omitted) Name() string {
+	return "… " + plural(o.count, "more entry", "more entries")
+}
+
+func (o *omitted) Path() string {
+	return o.parent.Path() + "/" + o.Name()
+}
+
+func (o *omitted) FileType() string {
+	return ""
+}
+
+func (o *omitted) IsLast() bool {
+	return true
+}
+
+func (o *omitted) Parent() (FileInfo, bool) {
+	return o.parent, true
+}
+
+func (o *omitted) IsDir() bool {
+	return false
+}
+
+func (o *omitted) IsSym() bool {
+	return false
+}
+
+func (o *omitted) SymLink() (string, error) {
+	return "", xerrors.New("This is not symlink")
+}
+
+func (o *omitted) SetError(err error) {
+	o.err = err
+}
+
+func (o *omitted) Error() error {
+	return o.err
+}
+
+func (o *omitted) SetHash(hash string) {
+}
+
+func (o *omitted) Hash() string {
+	return ""
+}
+
+func (o *omitted) SetLines(lines int) {
+}
+
+func (o *omitted) Lines() (int, bool) {
+	return 0, false
+}
+
+func (o *omitted) SetKind(kind FileKind) {
+}
+
+func (o *omitted) Kind() FileKind {
+	return kindText
+}
+
+// virtualDir is a directory which doesn't exist on the disk, such as the top of multiple roots.
+type virtualDir struct {
+	name string
+	err  error
+
+	lines   int
+	counted bool
+}
+
+var _ FileInfo = (*virtualDir)(nil)
+
+func newVirtualDir(name string) FileInfo {
+	return &virtualDir{
+		name: name,
+	}
+}
+
+// isVirtualDir returns true, when f is the directory which doesn't exist on the disk.
+func isVirtualDir(f FileInfo) bool {
+	_, ok := f.(*virtualDir)
+	return ok
+}
+
+func (v *virtualDir) Name() string {
+	return v.name
+}
+
+func (v *virtualDir) Path() string {
+	return v.name
+}
+
+func (v *virtualDir) FileType() string {
+	return ""
+}
+
+func (v *virtualDir) IsLast() bool {
+	return true
+}
+
+func (v *virtualDir) Parent() (FileInfo, bool) {
+	return nil, false
+}
+
+func (v *virtualDir) IsDir() bool {
+	return true
+}
+
+func (v *virtualDir) IsSym() bool {
+	return false
+}
+
+func (v *virtualDir) SymLink() (string, error) {
+	return "", xerrors.New("This is not symlink")
+}
+
+func (v *virtualDir) SetError(err error) {
+	v.err = err
+}
+
+func (v *virtualDir) Error() error {
+	return v.err
+}
+
+func (v *virtualDir) SetHash(hash string) {
+}
+
+func (v *virtualDir) Hash() string {
+	return ""
+}
+
+func (v *virtualDir) SetLines(lines int) {
+	v.lines = lines
+	v.counted = true
+}
+
+func (v *virtualDir) Lines() (int, bool) {
+	return v.lines, v.counted
+}
+
+func (v *virtualDir) SetKind(kind FileKind) {
+}
+
+func (v *virtualDir) Kind() FileKind {
+	return kindText
 }
@@ -1,4 +1,4 @@
-package main
+package gtree
 
 import (
 	"os"
@@ -120,28 +120,3 @@ func TestFileInfo_Parent(t *testing.T) {
 		}
 	}
 }
-
-func TestFileInfo_ChildPrefix(t *testing.T) {
-	root := NewFileInfo(newDummyOsFile("root", true), nil, false)
-	f1 := NewFileInfo(newDummyOsFile("test.go", false), nil, false)
-	f2 := NewFileInfo(newDummyOsFile("test.go", false), root, false)
-	d1 := NewFileInfo(newDummyOsFile("test", true), root, false)
-	d2 := NewFileInfo(newDummyOsFile("test", true), root, true)
-
-	inputs := []struct {
-		file        FileInfo
-		childPrefix string
-	}{
-		{root, ""},
-		{f1, ""},
-		{f2, ""},
-		{d1, "│   "},
-		{d2, "    "},
-	}
-
-	for _, input := range inputs {
-		if input.file.ChildPrefix() != input.childPrefix {
-			t.Errorf("FileInfo.ChildPrefix expect %s, but got %s", input.childPrefix, input.file.ChildPrefix())
-		}
-	}
-}
@@ -0,0 +1,254 @@
+package gtree
+
+import (
+	"io"
+	"sort"
+)
+
+// Entry is a file of the tree passed to Formatter
+// with the data which is known from the whole tree.
+type Entry struct {
+	FileInfo
+
+	// Depth is the depth from the root. The root is 0.
+	Depth int
+
+	// RelPath is the path from the root. The root is ".".
+	RelPath string
+
+	// Annotation is the description by '--annotate' option.
+	Annotation string
+
+	// Duplicates is the number of files which have the same digest by '--dup' option.
+	// It is 0, when the file isn't duplicated.
+	Duplicates int
+}
+
+// Formatter writes the trees in an output format.
+// The callbacks are called in the order of the trees.
+type Formatter interface {
+	// Begin is called once before the first tree.
+	Begin(w io.Writer) error
+
+	// EnterDir is called for the directory before its descendants.
+	EnterDir(w io.Writer, e *Entry) error
+
+	// Entry is called for the file which isn't directory.
+	Entry(w io.Writer, e *Entry) error
+
+	// LeaveDir is called for the directory after its descendants.
+	LeaveDir(w io.Writer, e *Entry) error
+
+	// End is called once after the last tree with the report which counts all trees.
+	End(w io.Writer, r *Report) error
+}
+
+// treeFormatter is the Formatter which uses the whole tree before writing,
+// such as to align annotations of siblings.
+type treeFormatter interface {
+	SetDuplicates(duplicates map[string]int)
+	SetAnnotations(a annotations, files []FileInfo)
+}
+
+// defaultFormat is the output format when '--format' option isn't specified.
+const defaultFormat = "text"
+
+// formatters is the output formats selected by '--format' option.
+var formatters = map[string]func(opt *ListDisplayOptions) Formatter{
+	"text": func(opt *ListDisplayOptions) Formatter {
+		return NewPrinter(opt)
+	},
+	"markdown": func(opt *ListDisplayOptions) Formatter {
+		return NewMarkdownPrinter(opt)
+	},
+	"dot": func(opt *ListDisplayOptions) Formatter {
+		return NewDotFormatter(opt)
+	},
+	"mermaid": func(opt *ListDisplayOptions) Formatter {
+		return NewMermaidFormatter(opt)
+	},
+	"ndjson": func(opt *ListDisplayOptions) Formatter {
+		return NewNDJSONFormatter()
+	},
+	"csv": func(opt *ListDisplayOptions) Formatter {
+		return NewCSVFormatter(opt, ',')
+	},
+	"tsv": func(opt *ListDisplayOptions) Formatter {
+		return NewCSVFormatter(opt, '\t')
+	},
+}
+
+// isTextFormat returns true, when the format is read by humans,
+// so that the report and the statistics can follow the trees.
+func isTextFormat(name string) bool {
+	return name == "" || name == "text" || name == "markdown"
+}
+
+// RegisterFormatter adds the output format which is selected by '--format name'.
+// This is called by programs which use this package before Run.
+// The format whose name is already registered is replaced.
+func RegisterFormatter(name string, newFormatter func(opt *ListDisplayOptions) Formatter) {
+	formatters[name] = newFormatter
+}
+
+// formatterOf returns the registered output format.
+// The empty name is the default format.
+func formatterOf(name string) (func(opt *ListDisplayOptions) Formatter, bool) {
+	if name == "" {
+		name = defaultFormat
+	}
+	f, ok := formatters[name]
+	return f, ok
+}
+
+// formatNames returns the names of the registered formats.
+func formatNames() []string {
+	names := make([]string, 0, len(formatters))
+	for name := range formatters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// treeWriter writes the trees of the output with one formatter.
+// Begin is called before the first tree, and End is called after the last tree
+// with the report which counts all trees.
+type treeWriter struct {
+	w         io.Writer
+	opt       *ListDisplayOptions
+	formatter Formatter
+	report    *Report
+}
+
+// newTreeWriter returns treeWriter pointer whose formatter has begun.
+func newTreeWriter(w io.Writer, opt *ListDisplayOptions, r *Report) (*treeWriter, error) {
+	var f Formatter
+	if isStatsDocument(opt) {
+		f = &statsFormatter{opt: opt}
+	} else {
+		newFormatter, _ := formatterOf(opt.Format)
+		f = newFormatter(opt)
+	}
+	if err := f.Begin(w); err != nil {
+		return nil, err
+	}
+	return &treeWriter{w: w, opt: opt, formatter: f, report: r}, nil
+}
+
+// writeTree writes the files received from ch as a tree, and counts them into the report.
+// a is the descriptions of the files in the tree.
+func (tw *treeWriter) writeTree(ch <-chan FileInfo, a annotations) error {
+	// Duplicates are known only after all files are hashed,
+	// annotations are aligned after all siblings are found,
+	// and lines of directories are known after all descendants are counted.
+	if tw.opt.IsDup() || a != nil || tw.opt.IsLines() {
+		files := make([]FileInfo, 0)
+		for file := range ch {
+			files = append(files, file)
+		}
+		return tw.writeFiles(files, a)
+	}
+
+	fw := newFormatWriter(tw.formatter)
+	for file := range ch {
+		tw.report.add(file)
+		if err := fw.write(tw.w, file); err != nil {
+			return err
+		}
+	}
+	return fw.close(tw.w)
+}
+
+// writeFiles writes all files of a tree in the order of the tree, and counts them into the report.
+// a is the descriptions of the files in the tree.
+func (tw *treeWriter) writeFiles(files []FileInfo, a annotations) error {
+	files = arrangeLines(files, tw.opt)
+
+	fw := newFormatWriter(tw.formatter)
+	fw.annotations = a
+	if tw.opt.IsDup() {
+		fw.duplicates = findDuplicates(files)
+	}
+
+	if tf, ok := tw.formatter.(treeFormatter); ok {
+		if fw.duplicates != nil {
+			tf.SetDuplicates(fw.duplicates)
+		}
+		if a != nil {
+			tf.SetAnnotations(a, files)
+		}
+	}
+
+	for _, file := range files {
+		tw.report.add(file)
+		if err := fw.write(tw.w, file); err != nil {
+			return err
+		}
+	}
+	return fw.close(tw.w)
+}
+
+// close ends the formatter with the report of all trees.
+func (tw *treeWriter) close() error {
+	return tw.formatter.End(tw.w, tw.report)
+}
+
+// formatWriter calls the callbacks of Formatter for the files received in the order of the tree.
+type formatWriter struct {
+	formatter   Formatter
+	duplicates  map[string]int
+	annotations annotations
+
+	// dirs is the directories whose descendants are being written.
+	dirs []*Entry
+}
+
+func newFormatWriter(f Formatter) *formatWriter {
+	return &formatWriter{formatter: f}
+}
+
+// write leaves the directories which aren't the ancestors of file, and writes file.
+func (fw *formatWriter) write(w io.Writer, file FileInfo) error {
+	parent, ok := file.Parent()
+	for len(fw.dirs) > 0 && (!ok || fw.dirs[len(fw.dirs)-1].FileInfo != parent) {
+		if err := fw.leave(w); err != nil {
+			return err
+		}
+	}
+
+	e := &Entry{
+		FileInfo: file,
+		Depth:    len(fw.dirs),
+		RelPath:  relativePath(file),
+	}
+	if d, ok := fw.annotations.lookup(file); ok {
+		e.Annotation = d
+	}
+	if h := file.Hash(); h != "" {
+		e.Duplicates = fw.duplicates[h]
+	}
+
+	if !file.IsDir() {
+		return fw.formatter.Entry(w, e)
+	}
+
+	fw.dirs = append(fw.dirs, e)
+	return fw.formatter.EnterDir(w, e)
+}
+
+func (fw *formatWriter) leave(w io.Writer) error {
+	e := fw.dirs[len(fw.dirs)-1]
+	fw.dirs = fw.dirs[:len(fw.dirs)-1]
+	return fw.formatter.LeaveDir(w, e)
+}
+
+// close leaves all directories.
+func (fw *formatWriter) close(w io.Writer) error {
+	for len(fw.dirs) > 0 {
+		if err := fw.leave(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
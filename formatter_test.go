@@ -0,0 +1,170 @@
+package gtree
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// recordFormatter records the callbacks of Formatter.
+type recordFormatter struct {
+	calls []string
+}
+
+func (f *recordFormatter) Begin(w io.Writer) error {
+	f.calls = append(f.calls, "begin")
+	return nil
+}
+
+func (f *recordFormatter) EnterDir(w io.Writer, e *Entry) error {
+	f.calls = append(f.calls, fmt.Sprintf("enter %s %d", e.RelPath, e.Depth))
+	return nil
+}
+
+func (f *recordFormatter) Entry(w io.Writer, e *Entry) error {
+	f.calls = append(f.calls, fmt.Sprintf("entry %s %d", e.RelPath, e.Depth))
+	return nil
+}
+
+func (f *recordFormatter) LeaveDir(w io.Writer, e *Entry) error {
+	f.calls = append(f.calls, fmt.Sprintf("leave %s", e.RelPath))
+	return nil
+}
+
+func (f *recordFormatter) End(w io.Writer, r *Report) error {
+	f.calls = append(f.calls, fmt.Sprintf("end %s", r))
+	return nil
+}
+
+// printTree writes the files received from ch as the only tree of the output like showTrees, and counts them into r.
+func printTree(w io.Writer, ch <-chan FileInfo, opts Options, r *Report) error {
+	displayOptions := opts.ListOptions.ListDisplayOptions
+	tw, err := newTreeWriter(w, displayOptions, r)
+	if err != nil {
+		return err
+	}
+
+	if err := tw.writeTree(ch, displayOptions.annotations); err != nil {
+		return err
+	}
+	return tw.close()
+}
+
+func TestRegisterFormatter(t *testing.T) {
+	f := &recordFormatter{}
+	RegisterFormatter("record", func(opt *ListDisplayOptions) Formatter {
+		return f
+	})
+	defer delete(formatters, "record")
+
+	opts := Options{ListOptions: &ListOptions{
+		ListSearchOptions:  &ListSearchOptions{},
+		ListDisplayOptions: &ListDisplayOptions{Format: "record"},
+	}}
+
+	ch := NodeTree(newTestNodes(), opts.ListOptions.ListSearchOptions)
+	if err := printTree(new(bytes.Buffer), ch, opts, &Report{}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"begin",
+		"enter . 0",
+		"enter a 1",
+		"entry a/b.txt 2",
+		"enter a/c 2",
+		"entry a/c/d.go 3",
+		"leave a/c",
+		"leave a",
+		"enter e 1",
+		"leave e",
+		"entry f.go 1",
+		"leave .",
+		"end 3 directories, 3 files",
+	}
+	if !reflect.DeepEqual(f.calls, expected) {
+		t.Errorf("expected %v, but got %v", expected, f.calls)
+	}
+}
+
+func TestValidateOptions_Format(t *testing.T) {
+	tests := map[string]struct {
+		format string
+		report bool
+		err    bool
+	}{
+		"text":              {"text", false, false},
+		"markdown":          {"markdown", false, false},
+		"default":           {"", false, false},
+		"unknown":           {"xml", false, true},
+		"report on text":    {"text", true, false},
+		"report on ndjson":  {"ndjson", true, true},
+		"report on mermaid": {"mermaid", true, true},
+	}
+
+	for key, tt := range tests {
+		t.Run(key, func(t *testing.T) {
+			display := &ListDisplayOptions{Format: tt.format, Indent: 4}
+			if tt.report {
+				display.Report = []bool{true}
+			}
+			opts := Options{ListOptions: &ListOptions{ListSearchOptions: &ListSearchOptions{}, ListDisplayOptions: display}}
+			err := validateOptions(opts)
+			if (err != nil) != tt.err {
+				t.Fatalf("validateOptions() expected error %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestShowTrees_Formatter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gtree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeTestFiles(t, dir, map[string]string{
+		"a/x.txt": "x",
+		"b/y.txt": "y",
+	})
+
+	f := &recordFormatter{}
+	formatters["record"] = func(opt *ListDisplayOptions) Formatter {
+		return f
+	}
+	defer delete(formatters, "record")
+
+	opts := Options{ListOptions: &ListOptions{
+		ListSearchOptions:  &ListSearchOptions{},
+		ListDisplayOptions: &ListDisplayOptions{Format: "record", Indent: 4},
+	}}
+	if err := validateOptions(opts); err != nil {
+		t.Fatal(err)
+	}
+
+	roots := []string{filepath.Join(dir, "a"), filepath.Join(dir, "b")}
+	if err := showTrees(new(bytes.Buffer), roots, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	// The formatter begins and ends once for all trees.
+	expected := []string{
+		"begin",
+		"enter . 0",
+		"entry x.txt 1",
+		"leave .",
+		"enter . 0",
+		"entry y.txt 1",
+		"leave .",
+		"end 0 directories, 2 files",
+	}
+	if !reflect.DeepEqual(f.calls, expected) {
+		t.Errorf("expected %v, but got %v", expected, f.calls)
+	}
+}
@@ -3,7 +3,11 @@ module github.com/kitagry/gtree
 go 1.13
 
 require (
+	github.com/BurntSushi/toml v0.3.1
+	github.com/cespare/xxhash/v2 v2.1.1
 	github.com/gookit/color v1.2.1
 	github.com/jessevdk/go-flags v1.4.0
+	golang.org/x/term v0.0.0-20201126162022-7de9c90e9dd1
 	golang.org/x/xerrors v0.0.0-20191204190536-9bdfabe68543
+	gopkg.in/yaml.v2 v2.3.0
 )
@@ -0,0 +1,228 @@
+package gtree
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/xerrors"
+)
+
+// goModFile is the directives of go.mod which are needed to locate packages.
+type goModFile struct {
+	// dir is the directory which has go.mod.
+	dir string
+
+	// path is the module path.
+	path string
+
+	// require is the versions of the required modules keyed by the module path.
+	require map[string]string
+
+	// replace is the replacements keyed by the module path.
+	replace map[string]goModReplace
+}
+
+// goModReplace is the replacement of a module by another module or a local directory.
+type goModReplace struct {
+	path    string
+	version string
+}
+
+// isLocal returns true, when the module is replaced by the local directory.
+func (r goModReplace) isLocal() bool {
+	return r.version == "" && (strings.HasPrefix(r.path, ".") || filepath.IsAbs(r.path))
+}
+
+// findModule returns go.mod in dir or its ancestors.
+func findModule(dir string) (*goModFile, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to find module: %w", err)
+	}
+
+	for d := abs; ; d = filepath.Dir(d) {
+		gomod := filepath.Join(d, "go.mod")
+		if _, err := os.Stat(gomod); err == nil {
+			mod, err := readGoMod(gomod)
+			if err != nil {
+				return nil, err
+			}
+			mod.dir = d
+			return mod, nil
+		}
+
+		if filepath.Dir(d) == d {
+			return nil, xerrors.Errorf("go.mod is not found in %s or its parents", dir)
+		}
+	}
+}
+
+func readGoMod(gomod string) (*goModFile, error) {
+	f, err := os.Open(gomod)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read go.mod: %w", err)
+	}
+	defer f.Close()
+
+	return parseGoMod(f)
+}
+
+// parseGoMod parses module, require and replace directives of go.mod.
+// Both the single line form and the block form are supported.
+func parseGoMod(r io.Reader) (*goModFile, error) {
+	mod := &goModFile{
+		require: make(map[string]string),
+		replace: make(map[string]goModReplace),
+	}
+
+	block := ""
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if block != "" {
+			if fields[0] == ")" {
+				block = ""
+				continue
+			}
+			mod.directive(block, fields)
+			continue
+		}
+
+		if len(fields) == 2 && fields[1] == "(" {
+			block = fields[0]
+			continue
+		}
+		mod.directive(fields[0], fields[1:])
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, xerrors.Errorf("failed to read go.mod: %w", err)
+	}
+
+	if mod.path == "" {
+		return nil, xerrors.New("module directive is not found in go.mod")
+	}
+	return mod, nil
+}
+
+func (m *goModFile) directive(verb string, args []string) {
+	for i, a := range args {
+		if s, err := strconv.Unquote(a); err == nil {
+			args[i] = s
+		}
+	}
+
+	switch verb {
+	case "module":
+		if len(args) >= 1 {
+			m.path = args[0]
+		}
+	case "require":
+		if len(args) >= 2 {
+			m.require[args[0]] = args[1]
+		}
+	case "replace":
+		// 'old [version] => new [version]'
+		for i, a := range args {
+			if a != "=>" || i == 0 || i+1 >= len(args) {
+				continue
+			}
+
+			r := goModReplace{path: args[i+1]}
+			if i+2 < len(args) {
+				r.version = args[i+2]
+			}
+			m.replace[args[0]] = r
+		}
+	}
+}
+
+// provides returns true, when the package is in the module itself.
+func (m *goModFile) provides(pkgPath string) bool {
+	return pkgPath == m.path || strings.HasPrefix(pkgPath, m.path+"/")
+}
+
+// module returns the required module which provides the package, and its version.
+func (m *goModFile) module(pkgPath string) (string, string, bool) {
+	found := ""
+	for path := range m.require {
+		if (pkgPath == path || strings.HasPrefix(pkgPath, path+"/")) && len(path) > len(found) {
+			found = path
+		}
+	}
+
+	if found == "" {
+		return "", "", false
+	}
+	return found, m.require[found], true
+}
+
+// packageDir returns the directory of the package which isn't in the standard library.
+// The packages of required modules are located in the module cache.
+func (m *goModFile) packageDir(pkgPath string) (string, error) {
+	if m.provides(pkgPath) {
+		return filepath.Join(m.dir, filepath.FromSlash(strings.TrimPrefix(pkgPath, m.path))), nil
+	}
+
+	modPath, version, ok := m.module(pkgPath)
+	if !ok {
+		return "", xerrors.Errorf("no required module provides package %s", pkgPath)
+	}
+	rest := filepath.FromSlash(strings.TrimPrefix(pkgPath, modPath))
+
+	if r, ok := m.replace[modPath]; ok {
+		if r.isLocal() {
+			dir := r.path
+			if !filepath.IsAbs(dir) {
+				dir = filepath.Join(m.dir, dir)
+			}
+			return filepath.Join(dir, rest), nil
+		}
+		modPath, version = r.path, r.version
+	}
+
+	return filepath.Join(moduleCache(), escapeModulePath(modPath)+"@"+escapeModulePath(version), rest), nil
+}
+
+// moduleCache returns the directory of the module cache.
+func moduleCache() string {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir
+	}
+
+	gopath := filepath.SplitList(os.Getenv("GOPATH"))
+	if len(gopath) == 0 || gopath[0] == "" {
+		home, _ := os.UserHomeDir()
+		return filepath.Join(home, "go", "pkg", "mod")
+	}
+	return filepath.Join(gopath[0], "pkg", "mod")
+}
+
+// escapeModulePath escapes upper case letters such as 'BurntSushi' to '!burnt!sushi'
+// in the same way as the module cache.
+func escapeModulePath(path string) string {
+	var b strings.Builder
+	for _, r := range path {
+		if unicode.IsUpper(r) {
+			b.WriteRune('!')
+			b.WriteRune(unicode.ToLower(r))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,105 @@
+package gtree
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseGoMod(t *testing.T) {
+	gomod := `module github.com/kitagry/gtree // comment
+
+go 1.13
+
+require (
+	github.com/BurntSushi/toml v0.3.1
+	github.com/gookit/color v1.2.1 // indirect
+)
+
+require golang.org/x/xerrors v0.0.0-20191204190536-9bdfabe68543
+
+replace github.com/gookit/color => ../color
+
+replace (
+	golang.org/x/xerrors v0.0.0-20191204190536-9bdfabe68543 => github.com/fork/xerrors v1.0.0
+)
+`
+
+	mod, err := parseGoMod(strings.NewReader(gomod))
+	if err != nil {
+		t.Fatalf("parseGoMod() unexpected error: %v", err)
+	}
+
+	if mod.path != "github.com/kitagry/gtree" {
+		t.Errorf("parseGoMod() path expected 'github.com/kitagry/gtree', got '%s'", mod.path)
+	}
+
+	expectRequire := map[string]string{
+		"github.com/BurntSushi/toml": "v0.3.1",
+		"github.com/gookit/color":    "v1.2.1",
+		"golang.org/x/xerrors":       "v0.0.0-20191204190536-9bdfabe68543",
+	}
+	if !reflect.DeepEqual(mod.require, expectRequire) {
+		t.Errorf("parseGoMod() require expected %v, got %v", expectRequire, mod.require)
+	}
+
+	expectReplace := map[string]goModReplace{
+		"github.com/gookit/color": {path: "../color"},
+		"golang.org/x/xerrors":    {path: "github.com/fork/xerrors", version: "v1.0.0"},
+	}
+	if !reflect.DeepEqual(mod.replace, expectReplace) {
+		t.Errorf("parseGoMod() replace expected %v, got %v", expectReplace, mod.replace)
+	}
+
+	if _, err := parseGoMod(strings.NewReader("go 1.13\n")); err == nil {
+		t.Errorf("parseGoMod() expected error without module directive")
+	}
+}
+
+func TestGoModFile_PackageDir(t *testing.T) {
+	cache := filepath.Join(string(filepath.Separator), "cache")
+	defer os.Setenv("GOMODCACHE", os.Getenv("GOMODCACHE"))
+	os.Setenv("GOMODCACHE", cache)
+
+	dir := filepath.Join(string(filepath.Separator), "src", "m")
+	mod := &goModFile{
+		dir:  dir,
+		path: "example.com/m",
+		require: map[string]string{
+			"github.com/BurntSushi/toml": "v0.3.1",
+			"example.com/local":          "v1.0.0",
+			"example.com/fork":           "v1.0.0",
+			"example.com/fork/v2":        "v2.0.0",
+		},
+		replace: map[string]goModReplace{
+			"example.com/local": {path: "../local"},
+			"example.com/fork":  {path: "example.com/other", version: "v1.1.0"},
+		},
+	}
+
+	tests := map[string]string{
+		"example.com/m":                    dir,
+		"example.com/m/sub":                filepath.Join(dir, "sub"),
+		"github.com/BurntSushi/toml/query": filepath.Join(cache, "github.com/!burnt!sushi/toml@v0.3.1/query"),
+		"example.com/local/pkg":            filepath.Join(dir, "../local/pkg"),
+		"example.com/fork/pkg":             filepath.Join(cache, "example.com/other@v1.1.0/pkg"),
+		"example.com/fork/v2/pkg":          filepath.Join(cache, "example.com/fork/v2@v2.0.0/pkg"),
+	}
+
+	for path, expect := range tests {
+		got, err := mod.packageDir(path)
+		if err != nil {
+			t.Errorf("%s: packageDir() unexpected error: %v", path, err)
+			continue
+		}
+		if got != expect {
+			t.Errorf("%s: packageDir() expected '%s', got '%s'", path, expect, got)
+		}
+	}
+
+	if _, err := mod.packageDir("example.com/unknown"); err == nil {
+		t.Errorf("packageDir() expected error for the module which isn't required")
+	}
+}
@@ -0,0 +1,248 @@
+package gtree
+
+import (
+	"bufio"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// GoCommand is options for 'gtree go' command.
+type GoCommand struct {
+	Exported []bool `long:"exported" description:"List exported types and functions of each package."`
+}
+
+// IsExported returns true, if user specify '--exported' option.
+func (g *GoCommand) IsExported() bool {
+	return len(g.Exported) != 0
+}
+
+// goPackages builds the tree of Go packages under dir.
+// The package name, the number of files, the presence of tests and the doc summary
+// are returned as annotations.
+func goPackages(dir string, cmd *GoCommand) (*Node, annotations, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("failed to open %s: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return nil, nil, xerrors.Errorf("%s is not a directory", dir)
+	}
+
+	root := NewNode(dir, "", true)
+	a := make(annotations)
+	loadGoDir(root, dir, ".", a, cmd)
+	return root, a, nil
+}
+
+// loadGoDir adds the package in dir and the packages under dir to n.
+// It returns true, when dir or its descendants have any package.
+func loadGoDir(n *Node, dir, rel string, a annotations, cmd *GoCommand) bool {
+	found := false
+
+	pkg, err := build.ImportDir(dir, build.ImportComment)
+	if _, ok := err.(*build.NoGoError); !ok {
+		found = true
+		if err != nil {
+			n.SetError(err)
+		} else {
+			a[rel] = packageSummary(pkg)
+		}
+
+		if err == nil && cmd.IsExported() {
+			addExported(n, pkg, rel, a)
+		}
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		n.SetError(err)
+		return true
+	}
+
+	for _, f := range files {
+		if !f.IsDir() || skipGoDir(dir, f.Name()) {
+			continue
+		}
+
+		child := NewNode(f.Name(), "", true)
+		if loadGoDir(child, filepath.Join(dir, f.Name()), joinRel(rel, f.Name()), a, cmd) {
+			n.Add(child)
+			found = true
+		}
+	}
+	return found
+}
+
+// skipGoDir returns true, when the directory is ignored by the go command, or is another module.
+func skipGoDir(parent, name string) bool {
+	if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "vendor" {
+		return true
+	}
+
+	_, err := os.Stat(filepath.Join(parent, name, "go.mod"))
+	return err == nil
+}
+
+// joinRel returns the relative path of name in the directory rel.
+func joinRel(rel, name string) string {
+	if rel == "." {
+		return name
+	}
+	return rel + "/" + name
+}
+
+// packageSummary returns the description of the package such as
+// 'package main, 3 files, tests: Command gtree lists files.'
+func packageSummary(pkg *build.Package) string {
+	files := len(pkg.GoFiles) + len(pkg.CgoFiles)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "package %s, %s", pkg.Name, plural(files, "file", "files"))
+	if len(pkg.TestGoFiles)+len(pkg.XTestGoFiles) > 0 {
+		b.WriteString(", tests")
+	} else {
+		b.WriteString(", no tests")
+	}
+
+	if pkg.Doc != "" {
+		b.WriteString(": " + pkg.Doc)
+	}
+	return b.String()
+}
+
+// goDecl is an exported type or function.
+type goDecl struct {
+	kind string
+	name string
+	doc  string
+}
+
+// addExported adds the exported types and functions of pkg to n as leaves.
+func addExported(n *Node, pkg *build.Package, rel string, a annotations) {
+	fset := token.NewFileSet()
+	decls := make([]goDecl, 0)
+	for _, name := range append(append([]string{}, pkg.GoFiles...), pkg.CgoFiles...) {
+		f, err := parser.ParseFile(fset, filepath.Join(pkg.Dir, name), nil, parser.ParseComments)
+		if err != nil {
+			n.SetError(err)
+			continue
+		}
+		decls = append(decls, exportedDecls(f)...)
+	}
+
+	// Types precede functions.
+	sort.SliceStable(decls, func(i, j int) bool {
+		if decls[i].kind != decls[j].kind {
+			return decls[i].kind == "type"
+		}
+		return decls[i].name < decls[j].name
+	})
+
+	for _, d := range decls {
+		leaf := n.Add(NewNode(d.kind+" "+d.name, d.kind, false))
+		if d.doc != "" {
+			a[joinRel(rel, leaf.name)] = d.doc
+		}
+	}
+}
+
+// exportedDecls returns the exported types and functions which have no receiver in f.
+func exportedDecls(f *ast.File) []goDecl {
+	result := make([]goDecl, 0)
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil && d.Name.IsExported() {
+				result = append(result, goDecl{kind: "func", name: d.Name.Name, doc: doc.Synopsis(d.Doc.Text())})
+			}
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				s := spec.(*ast.TypeSpec)
+				if !s.Name.IsExported() {
+					continue
+				}
+
+				text := s.Doc.Text()
+				if text == "" {
+					text = d.Doc.Text()
+				}
+				result = append(result, goDecl{kind: "type", name: s.Name.Name, doc: doc.Synopsis(text)})
+			}
+		}
+	}
+	return result
+}
+
+// showGoTrees writes the package trees of the Go modules which have roots.
+func showGoTrees(out io.Writer, roots []string, opts Options) error {
+	for _, root := range roots {
+		if _, err := findModule(root); err != nil {
+			return err
+		}
+	}
+
+	w := bufio.NewWriter(out)
+	// The trees written before an error are shown. The error of flushing is returned at the end.
+	defer w.Flush()
+
+	displayOptions := opts.ListOptions.ListDisplayOptions
+	tw, err := newTreeWriter(w, displayOptions, newReport(displayOptions))
+	if err != nil {
+		return err
+	}
+
+	for _, root := range roots {
+		tree, a, err := goPackages(root, opts.GoCommand)
+		if err != nil {
+			return err
+		}
+
+		if err := tw.writeTree(NodeTree(tree, opts.ListOptions.ListSearchOptions), mergeAnnotations(a, displayOptions.annotations)); err != nil {
+			return err
+		}
+	}
+	if err := tw.close(); err != nil {
+		return err
+	}
+	return flushOutput(w)
+}
+
+// NodeTree starts sending the nodes under root, and returns the channel which receives them.
+func NodeTree(root *Node, opts *ListSearchOptions) chan FileInfo {
+	ch := make(chan FileInfo)
+	go nodeWalk(root, ch, opts)
+	return ch
+}
+
+// mergeAnnotations returns the annotations which have both a and preferred.
+// preferred is the annotations given by '--annotate' option.
+func mergeAnnotations(a, preferred annotations) annotations {
+	merged := make(annotations, len(a)+len(preferred))
+	for k, v := range a {
+		merged[k] = v
+	}
+	for k, v := range preferred {
+		merged[k] = v
+	}
+	return merged
+}
+
+// Usage returns the usage of 'gtree go' command.
+func (g *GoCommand) Usage() string {
+	return "[go-OPTIONS] [<directory list>]"
+}
@@ -0,0 +1,70 @@
+package gtree
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGoPackages(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gtree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"go.mod":                  "module example.com/m\n",
+		"main.go":                 "// Command m does nothing.\npackage main\n\nfunc main() {}\n",
+		"lib/lib.go":              "// Package lib is a library.\npackage lib\n\n// Lib is a type.\ntype Lib struct{}\n\n// New returns Lib.\nfunc New() Lib { return Lib{} }\n\nfunc private() {}\n",
+		"lib/lib_test.go":         "package lib\n",
+		"docs/README.md":          "# docs\n",
+		"testdata/x/x.go":         "package x\n",
+		"nested/go.mod":           "module example.com/nested\n",
+		"nested/nested.go":        "package nested\n",
+		"internal/empty/empty.md": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	root, a, err := goPackages(dir, &GoCommand{Exported: []bool{true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := make([]string, 0)
+	ch := make(chan FileInfo)
+	go nodeWalk(root, ch, &ListSearchOptions{})
+	for f := range ch {
+		names = append(names, relativePath(f))
+	}
+
+	expectNames := []string{".", "lib", "lib/type Lib", "lib/func New"}
+	if strings.Join(names, ",") != strings.Join(expectNames, ",") {
+		t.Errorf("goPackages() expected %v, got %v", expectNames, names)
+	}
+
+	expectAnnotations := annotations{
+		".":            "package main, 1 file, no tests: Command m does nothing.",
+		"lib":          "package lib, 1 file, tests: Package lib is a library.",
+		"lib/type Lib": "Lib is a type.",
+		"lib/func New": "New returns Lib.",
+	}
+	for k, v := range expectAnnotations {
+		if a[k] != v {
+			t.Errorf("goPackages() annotation of %s expected '%s', got '%s'", k, v, a[k])
+		}
+	}
+	if len(a) != len(expectAnnotations) {
+		t.Errorf("goPackages() expected %d annotations, got %v", len(expectAnnotations), a)
+	}
+}
@@ -0,0 +1,341 @@
+package gtree
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gookit/color"
+	"golang.org/x/xerrors"
+)
+
+// graphColors is the colors of diagram nodes for the terminal colors of icons.
+var graphColors = map[color.Color]string{
+	color.FgBlack:        "#4d4d4d",
+	color.FgRed:          "#e06c75",
+	color.FgGreen:        "#98c379",
+	color.FgYellow:       "#e5c07b",
+	color.FgBlue:         "#61afef",
+	color.FgMagenta:      "#c678dd",
+	color.FgCyan:         "#56b6c2",
+	color.FgWhite:        "#f0f0f0",
+	color.FgGray:         "#abb2bf",
+	color.FgLightRed:     "#f4a3a8",
+	color.FgLightGreen:   "#c3e88d",
+	color.FgLightYellow:  "#ffe9a8",
+	color.FgLightBlue:    "#a5d2f8",
+	color.FgLightMagenta: "#e2b8f0",
+	color.FgLightCyan:    "#a0e0e8",
+	color.FgLightWhite:   "#ffffff",
+}
+
+// graph has the states shared by the diagram formats.
+// Each file is a node whose id is 'n' and the sequential number, which is unique among all trees of the output.
+type graph struct {
+	opt *ListDisplayOptions
+
+	ids  map[FileInfo]string
+	next int
+
+	// children is the number of children of each directory being written.
+	children []int
+}
+
+func newGraph(opt *ListDisplayOptions) graph {
+	return graph{opt: opt, ids: make(map[FileInfo]string)}
+}
+
+func (g *graph) isCluster() bool {
+	return g.opt.GraphDirs == "cluster"
+}
+
+func (g *graph) isHints() bool {
+	return len(g.opt.GraphHints) != 0
+}
+
+// direction returns the direction of the layout such as 'TB' and 'LR'.
+func (g *graph) direction() string {
+	if g.opt.GraphDirection == "" {
+		return "TB"
+	}
+	return g.opt.GraphDirection
+}
+
+// id returns the node id of f.
+func (g *graph) id(f FileInfo) string {
+	if id, ok := g.ids[f]; ok {
+		return id
+	}
+	id := fmt.Sprintf("n%d", g.next)
+	g.next++
+	g.ids[f] = id
+	return id
+}
+
+// parentID returns the node id of the parent of f, if the parent is written.
+func (g *graph) parentID(f FileInfo) (string, bool) {
+	p, ok := f.Parent()
+	if !ok {
+		return "", false
+	}
+	id, ok := g.ids[p]
+	return id, ok
+}
+
+// enter counts e as a child of the current directory, and makes e the current directory if it is a directory.
+func (g *graph) enter(e *Entry) {
+	if len(g.children) > 0 {
+		g.children[len(g.children)-1]++
+	}
+	if e.IsDir() {
+		g.children = append(g.children, 0)
+	}
+}
+
+// leave returns the number of children of the current directory, and makes its parent the current directory.
+func (g *graph) leave() int {
+	n := g.children[len(g.children)-1]
+	g.children = g.children[:len(g.children)-1]
+	return n
+}
+
+// label returns the text of the node of f.
+func (g *graph) label(f FileInfo) (string, error) {
+	var b strings.Builder
+	if g.isHints() && !IsOmitted(f) {
+		b.WriteString(iconOf(f, g.opt.IsClassify()).Icon + " ")
+	}
+
+	if g.opt.IsFullPath() {
+		b.WriteString(g.opt.safeName(labelOf(f, f.Path())))
+	} else {
+		b.WriteString(g.opt.safeName(labelOf(f, f.Name())))
+	}
+
+	if f.IsSym() {
+		symLink, err := f.SymLink()
+		if err != nil {
+			return "", xerrors.Errorf("failed to retrieve symlink path: %w", err)
+		}
+		fmt.Fprintf(&b, " -> %s", g.opt.safeName(symLink))
+	}
+
+	if err := f.Error(); err != nil {
+		fmt.Fprintf(&b, " [%s]", g.opt.escape(err.Error(), false))
+	}
+	return b.String(), nil
+}
+
+// fill returns the color of the node of f by '--graph-hints' option.
+// The clusters are outlined with the color instead of filled.
+func (g *graph) fill(f FileInfo) (string, bool) {
+	if !g.isHints() || IsOmitted(f) {
+		return "", false
+	}
+	c, ok := graphColors[iconOf(f, g.opt.IsClassify()).Color]
+	return c, ok
+}
+
+func (g *graph) indent(depth int) string {
+	if g.isCluster() {
+		return strings.Repeat("\t", depth+1)
+	}
+	return "\t"
+}
+
+func writeGraph(w io.Writer, format string, a ...interface{}) error {
+	_, err := fmt.Fprintf(w, format, a...)
+	if err != nil {
+		return xerrors.Errorf("failed to write: %w", err)
+	}
+	return nil
+}
+
+var dotEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\r", `\r`,
+)
+
+// DotFormatter writes the tree as a Graphviz DOT graph.
+// Directories are the parent nodes of their children, or the clusters which contain them.
+type DotFormatter struct {
+	graph
+}
+
+// NewDotFormatter returns DotFormatter pointer.
+func NewDotFormatter(opt *ListDisplayOptions) *DotFormatter {
+	return &DotFormatter{graph: newGraph(opt)}
+}
+
+// Begin opens the graph.
+func (d *DotFormatter) Begin(w io.Writer) error {
+	return writeGraph(w, "digraph tree {\n\trankdir=%s;\n\tnode [shape=box];\n", d.direction())
+}
+
+// EnterDir writes the directory node, or opens the cluster of the directory.
+func (d *DotFormatter) EnterDir(w io.Writer, e *Entry) error {
+	if !d.isCluster() {
+		return d.Entry(w, e)
+	}
+
+	label, err := d.label(e)
+	if err != nil {
+		return err
+	}
+	d.enter(e)
+
+	indent := d.indent(e.Depth)
+	id := d.id(e.FileInfo)
+	if err := writeGraph(w, "%ssubgraph cluster_%s {\n%s\tlabel=\"%s\";\n", indent, id, indent, dotEscaper.Replace(label)); err != nil {
+		return err
+	}
+	if c, ok := d.fill(e); ok {
+		return writeGraph(w, "%s\tcolor=\"%s\";\n", indent, c)
+	}
+	return nil
+}
+
+// Entry writes the node of the file, and the edge from its parent.
+func (d *DotFormatter) Entry(w io.Writer, e *Entry) error {
+	label, err := d.label(e)
+	if err != nil {
+		return err
+	}
+	d.enter(e)
+
+	attrs := fmt.Sprintf("label=\"%s\"", dotEscaper.Replace(label))
+	if e.IsDir() {
+		attrs += " shape=folder"
+	}
+	if c, ok := d.fill(e); ok {
+		attrs += fmt.Sprintf(" style=filled fillcolor=\"%s\"", c)
+	}
+
+	indent := d.indent(e.Depth)
+	id := d.id(e.FileInfo)
+	if err := writeGraph(w, "%s%s [%s];\n", indent, id, attrs); err != nil {
+		return err
+	}
+
+	if parent, ok := d.parentID(e); ok && !d.isCluster() {
+		return writeGraph(w, "%s%s -> %s;\n", indent, parent, id)
+	}
+	return nil
+}
+
+// LeaveDir closes the cluster of the directory.
+// The empty cluster has the invisible node, since Graphviz doesn't draw empty clusters.
+func (d *DotFormatter) LeaveDir(w io.Writer, e *Entry) error {
+	children := d.leave()
+	if !d.isCluster() {
+		return nil
+	}
+
+	indent := d.indent(e.Depth)
+	if children == 0 {
+		if err := writeGraph(w, "%s\t%s_empty [label=\"\" shape=point style=invis];\n", indent, d.id(e.FileInfo)); err != nil {
+			return err
+		}
+	}
+	return writeGraph(w, "%s}\n", indent)
+}
+
+// End closes the graph.
+func (d *DotFormatter) End(w io.Writer, r *Report) error {
+	return writeGraph(w, "}\n")
+}
+
+var mermaidEscaper = strings.NewReplacer(
+	"#", "#35;",
+	`"`, "#quot;",
+	"<", "#lt;",
+	">", "#gt;",
+	"\n", " ",
+	"\r", " ",
+)
+
+// MermaidFormatter writes the tree as a Mermaid flowchart.
+// Directories are the parent nodes of their children, or the subgraphs which contain them.
+type MermaidFormatter struct {
+	graph
+}
+
+// NewMermaidFormatter returns MermaidFormatter pointer.
+func NewMermaidFormatter(opt *ListDisplayOptions) *MermaidFormatter {
+	return &MermaidFormatter{graph: newGraph(opt)}
+}
+
+// Begin writes the header of the flowchart.
+func (m *MermaidFormatter) Begin(w io.Writer) error {
+	return writeGraph(w, "flowchart %s\n", m.direction())
+}
+
+// EnterDir writes the directory node, or opens the subgraph of the directory.
+func (m *MermaidFormatter) EnterDir(w io.Writer, e *Entry) error {
+	if !m.isCluster() {
+		return m.Entry(w, e)
+	}
+
+	label, err := m.label(e)
+	if err != nil {
+		return err
+	}
+	m.enter(e)
+
+	indent := m.indent(e.Depth)
+	id := m.id(e.FileInfo)
+	if err := writeGraph(w, "%ssubgraph %s [\"%s\"]\n", indent, id, mermaidEscaper.Replace(label)); err != nil {
+		return err
+	}
+	if c, ok := m.fill(e); ok {
+		return writeGraph(w, "%s\tstyle %s stroke:%s\n", indent, id, c)
+	}
+	return nil
+}
+
+// Entry writes the node of the file, and the edge from its parent.
+// Directories are rectangles, and the other files are rounded rectangles.
+func (m *MermaidFormatter) Entry(w io.Writer, e *Entry) error {
+	label, err := m.label(e)
+	if err != nil {
+		return err
+	}
+	m.enter(e)
+
+	format := "%s%s(\"%s\")\n"
+	if e.IsDir() {
+		format = "%s%s[\"%s\"]\n"
+	}
+
+	indent := m.indent(e.Depth)
+	id := m.id(e.FileInfo)
+	if err := writeGraph(w, format, indent, id, mermaidEscaper.Replace(label)); err != nil {
+		return err
+	}
+
+	if parent, ok := m.parentID(e); ok && !m.isCluster() {
+		if err := writeGraph(w, "%s%s --> %s\n", indent, parent, id); err != nil {
+			return err
+		}
+	}
+	if c, ok := m.fill(e); ok {
+		return writeGraph(w, "%sstyle %s fill:%s\n", indent, id, c)
+	}
+	return nil
+}
+
+// LeaveDir closes the subgraph of the directory.
+func (m *MermaidFormatter) LeaveDir(w io.Writer, e *Entry) error {
+	m.leave()
+	if !m.isCluster() {
+		return nil
+	}
+	return writeGraph(w, "%send\n", m.indent(e.Depth))
+}
+
+// End does nothing, since the flowchart has no footer.
+func (m *MermaidFormatter) End(w io.Writer, r *Report) error {
+	return nil
+}
@@ -0,0 +1,200 @@
+package gtree
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGraphFormatters(t *testing.T) {
+	tests := map[string]struct {
+		displayOption *ListDisplayOptions
+		level         int
+		output        string
+	}{
+		"dot": {
+			displayOption: &ListDisplayOptions{Format: "dot"},
+			output: "digraph tree {\n" +
+				"\trankdir=TB;\n" +
+				"\tnode [shape=box];\n" +
+				"\tn0 [label=\"root\" shape=folder];\n" +
+				"\tn1 [label=\"a\" shape=folder];\n" +
+				"\tn0 -> n1;\n" +
+				"\tn2 [label=\"b.txt\"];\n" +
+				"\tn1 -> n2;\n" +
+				"\tn3 [label=\"c\" shape=folder];\n" +
+				"\tn1 -> n3;\n" +
+				"\tn4 [label=\"d.go\"];\n" +
+				"\tn3 -> n4;\n" +
+				"\tn5 [label=\"e\" shape=folder];\n" +
+				"\tn0 -> n5;\n" +
+				"\tn6 [label=\"f.go\"];\n" +
+				"\tn0 -> n6;\n" +
+				"}\n",
+		},
+		"dot clusters": {
+			displayOption: &ListDisplayOptions{Format: "dot", GraphDirs: "cluster", GraphDirection: "LR"},
+			output: "digraph tree {\n" +
+				"\trankdir=LR;\n" +
+				"\tnode [shape=box];\n" +
+				"\tsubgraph cluster_n0 {\n" +
+				"\t\tlabel=\"root\";\n" +
+				"\t\tsubgraph cluster_n1 {\n" +
+				"\t\t\tlabel=\"a\";\n" +
+				"\t\t\tn2 [label=\"b.txt\"];\n" +
+				"\t\t\tsubgraph cluster_n3 {\n" +
+				"\t\t\t\tlabel=\"c\";\n" +
+				"\t\t\t\tn4 [label=\"d.go\"];\n" +
+				"\t\t\t}\n" +
+				"\t\t}\n" +
+				"\t\tsubgraph cluster_n5 {\n" +
+				"\t\t\tlabel=\"e\";\n" +
+				"\t\t\tn5_empty [label=\"\" shape=point style=invis];\n" +
+				"\t\t}\n" +
+				"\t\tn6 [label=\"f.go\"];\n" +
+				"\t}\n" +
+				"}\n",
+		},
+		"dot with hints": {
+			displayOption: &ListDisplayOptions{Format: "dot", GraphHints: []bool{true}},
+			level:         1,
+			output: "digraph tree {\n" +
+				"\trankdir=TB;\n" +
+				"\tnode [shape=box];\n" +
+				"\tn0 [label=\"" + defaultFolderIcon.Icon + " root\" shape=folder style=filled fillcolor=\"#61afef\"];\n" +
+				"\tn1 [label=\"" + defaultFolderIcon.Icon + " a\" shape=folder style=filled fillcolor=\"#61afef\"];\n" +
+				"\tn0 -> n1;\n" +
+				"\tn2 [label=\"" + defaultFolderIcon.Icon + " e\" shape=folder style=filled fillcolor=\"#61afef\"];\n" +
+				"\tn0 -> n2;\n" +
+				"\tn3 [label=\"" + icons["go"].Icon + " f.go\" style=filled fillcolor=\"#f0f0f0\"];\n" +
+				"\tn0 -> n3;\n" +
+				"}\n",
+		},
+		"mermaid": {
+			displayOption: &ListDisplayOptions{Format: "mermaid"},
+			level:         1,
+			output: "flowchart TB\n" +
+				"\tn0[\"root\"]\n" +
+				"\tn1[\"a\"]\n" +
+				"\tn0 --> n1\n" +
+				"\tn2[\"e\"]\n" +
+				"\tn0 --> n2\n" +
+				"\tn3(\"f.go\")\n" +
+				"\tn0 --> n3\n",
+		},
+		"mermaid subgraphs": {
+			displayOption: &ListDisplayOptions{Format: "mermaid", GraphDirs: "cluster"},
+			output: "flowchart TB\n" +
+				"\tsubgraph n0 [\"root\"]\n" +
+				"\t\tsubgraph n1 [\"a\"]\n" +
+				"\t\t\tn2(\"b.txt\")\n" +
+				"\t\t\tsubgraph n3 [\"c\"]\n" +
+				"\t\t\t\tn4(\"d.go\")\n" +
+				"\t\t\tend\n" +
+				"\t\tend\n" +
+				"\t\tsubgraph n5 [\"e\"]\n" +
+				"\t\tend\n" +
+				"\t\tn6(\"f.go\")\n" +
+				"\tend\n",
+		},
+	}
+
+	for key, tt := range tests {
+		t.Run(key, func(t *testing.T) {
+			search := &ListSearchOptions{}
+			if tt.level != 0 {
+				search.Level = &tt.level
+			}
+			opts := Options{ListOptions: &ListOptions{ListSearchOptions: search, ListDisplayOptions: tt.displayOption}}
+
+			buffer := new(bytes.Buffer)
+			if err := printTree(buffer, NodeTree(newTestNodes(), search), opts, &Report{}); err != nil {
+				t.Fatal(err)
+			}
+			if buffer.String() != tt.output {
+				t.Errorf("expected\n%s\nbut got\n%s", tt.output, buffer.String())
+			}
+		})
+	}
+}
+
+func TestGraphFormatters_MultipleRoots(t *testing.T) {
+	tests := map[string]struct {
+		format string
+		output string
+	}{
+		"dot": {
+			format: "dot",
+			output: "digraph tree {\n" +
+				"\trankdir=TB;\n" +
+				"\tnode [shape=box];\n" +
+				"\tn0 [label=\"x\" shape=folder];\n" +
+				"\tn1 [label=\"a.go\"];\n" +
+				"\tn0 -> n1;\n" +
+				"\tn2 [label=\"y\" shape=folder];\n" +
+				"\tn3 [label=\"a.go\"];\n" +
+				"\tn2 -> n3;\n" +
+				"}\n",
+		},
+		"mermaid": {
+			format: "mermaid",
+			output: "flowchart TB\n" +
+				"\tn0[\"x\"]\n" +
+				"\tn1(\"a.go\")\n" +
+				"\tn0 --> n1\n" +
+				"\tn2[\"y\"]\n" +
+				"\tn3(\"a.go\")\n" +
+				"\tn2 --> n3\n",
+		},
+	}
+
+	for key, tt := range tests {
+		t.Run(key, func(t *testing.T) {
+			search := &ListSearchOptions{}
+			buffer := new(bytes.Buffer)
+			tw, err := newTreeWriter(buffer, &ListDisplayOptions{Format: tt.format}, &Report{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The trees are written in one graph, so that the node ids aren't duplicated.
+			for _, name := range []string{"x", "y"} {
+				root := NewNode(name, "", true)
+				root.Add(NewNode("a.go", "go", false))
+				if err := tw.writeTree(NodeTree(root, search), nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := tw.close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if buffer.String() != tt.output {
+				t.Errorf("expected\n%s\nbut got\n%s", tt.output, buffer.String())
+			}
+		})
+	}
+}
+
+func TestGraphEscape(t *testing.T) {
+	tests := map[string]struct {
+		name    string
+		dot     string
+		mermaid string
+	}{
+		"quote":     {`say "hi"`, `say \"hi\"`, "say #quot;hi#quot;"},
+		"backslash": {`a\b`, `a\\b`, `a\b`},
+		"newline":   {"a\nb", `a\nb`, "a b"},
+		"html":      {"<b>#1", "<b>#1", "#lt;b#gt;#35;1"},
+	}
+
+	for key, tt := range tests {
+		t.Run(key, func(t *testing.T) {
+			if got := dotEscaper.Replace(tt.name); got != tt.dot {
+				t.Errorf("dot expected %q, got %q", tt.dot, got)
+			}
+			if got := mermaidEscaper.Replace(tt.name); got != tt.mermaid {
+				t.Errorf("mermaid expected %q, got %q", tt.mermaid, got)
+			}
+		})
+	}
+}
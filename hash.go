@@ -0,0 +1,125 @@
+package gtree
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"os"
+	"runtime"
+
+	"github.com/cespare/xxhash/v2"
+	"golang.org/x/xerrors"
+)
+
+// shortHashLength is the number of hex characters shown when the hash is truncated.
+const shortHashLength = 12
+
+// newHash returns hash.Hash for the algorithm name.
+func newHash(algorithm string) (hash.Hash, error) {
+	switch algorithm {
+	case "sha256":
+		return sha256.New(), nil
+	case "md5":
+		return md5.New(), nil
+	case "xxhash":
+		return xxhash.New(), nil
+	}
+	return nil, xerrors.Errorf("unknown hash algorithm: %s", algorithm)
+}
+
+// hashFile returns the hex digest of the file content.
+func hashFile(path, algorithm string) (string, error) {
+	h, err := newHash(algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// fileJob is a file waiting for the work on its content.
+type fileJob struct {
+	file FileInfo
+	done chan struct{}
+}
+
+// HashFiles computes the digest of every regular file received from in
+// with a pool of workers, and sends the files to out in the received order.
+// It closes out when in is closed.
+func HashFiles(in <-chan FileInfo, out chan<- FileInfo, algorithm string) {
+	processFiles(in, out, func(f FileInfo) {
+		hash, err := hashFile(f.Path(), algorithm)
+		if err != nil {
+			f.SetError(err)
+		} else {
+			f.SetHash(hash)
+		}
+	})
+}
+
+// processFiles calls work for every regular file on the disk received from in with a pool of workers,
+// and sends the files to out in the received order.
+// It closes out when in is closed.
+func processFiles(in <-chan FileInfo, out chan<- FileInfo, work func(f FileInfo)) {
+	workers := runtime.NumCPU()
+	jobs := make(chan fileJob, workers)
+	pending := make(chan fileJob, workers*4)
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			for job := range jobs {
+				work(job.file)
+				close(job.done)
+			}
+		}()
+	}
+
+	go func() {
+		for f := range in {
+			job := fileJob{file: f, done: make(chan struct{})}
+			// Named pipes, sockets and devices aren't read, since reading them can block forever.
+			if info, ok := osFileInfo(f); !ok || !info.Mode().IsRegular() || f.Error() != nil {
+				close(job.done)
+			} else {
+				jobs <- job
+			}
+			pending <- job
+		}
+		close(jobs)
+		close(pending)
+	}()
+
+	for job := range pending {
+		<-job.done
+		out <- job.file
+	}
+	close(out)
+}
+
+// findDuplicates returns the number of files for each digest which appears more than once.
+func findDuplicates(files []FileInfo) map[string]int {
+	counts := make(map[string]int)
+	for _, f := range files {
+		if h := f.Hash(); h != "" {
+			counts[h]++
+		}
+	}
+
+	for h, c := range counts {
+		if c < 2 {
+			delete(counts, h)
+		}
+	}
+	return counts
+}
@@ -0,0 +1,57 @@
+//go:build linux
+// +build linux
+
+package gtree
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestProcessFiles_SkipFIFO(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gtree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "fifo")
+	if err := syscall.Mkfifo(path, 0644); err != nil {
+		t.Skipf("mkfifo isn't supported: %v", err)
+	}
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]func(in <-chan FileInfo, out chan<- FileInfo){
+		"hash": func(in <-chan FileInfo, out chan<- FileInfo) {
+			HashFiles(in, out, "sha256")
+		},
+		"lines": CountLines,
+	}
+
+	for key, process := range tests {
+		t.Run(key, func(t *testing.T) {
+			in := make(chan FileInfo, 1)
+			out := make(chan FileInfo)
+			in <- NewFileInfoForBase(info, nil, dir+"/", true)
+			close(in)
+			go process(in, out)
+
+			select {
+			case f := <-out:
+				if f.Hash() != "" || f.Error() != nil {
+					t.Errorf("expected the fifo to be skipped, got hash '%s' and error %v", f.Hash(), f.Error())
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("the fifo was read")
+			}
+		})
+	}
+}
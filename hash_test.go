@@ -0,0 +1,93 @@
+package gtree
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHashFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gtree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "hello.txt")
+	if err := ioutil.WriteFile(path, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"sha256": "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+		"md5":    "5d41402abc4b2a76b9719d911017c592",
+		"xxhash": "26c7827d889f6da3",
+	}
+
+	for algorithm, expect := range tests {
+		t.Run(algorithm, func(t *testing.T) {
+			got, err := hashFile(path, algorithm)
+			if err != nil {
+				t.Fatalf("hashFile() returns error: %v", err)
+			}
+			if got != expect {
+				t.Errorf("hashFile() expected %s, got %s", expect, got)
+			}
+		})
+	}
+
+	if _, err := hashFile(path, "unknown"); err == nil {
+		t.Errorf("hashFile() expected error for unknown algorithm")
+	}
+}
+
+func TestHashFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gtree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	names := []string{"a", "b", "c", "d"}
+	contents := []string{"same", "same", "other", "same"}
+	for i, name := range names {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(contents[i]), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	in := make(chan FileInfo)
+	out := make(chan FileInfo)
+	go func() {
+		for _, name := range names {
+			f, err := os.Stat(filepath.Join(dir, name))
+			if err != nil {
+				t.Error(err)
+				continue
+			}
+			in <- NewFileInfoForBase(f, nil, dir+"/", false)
+		}
+		close(in)
+	}()
+	go HashFiles(in, out, "md5")
+
+	files := make([]FileInfo, 0)
+	for f := range out {
+		files = append(files, f)
+	}
+
+	if len(files) != len(names) {
+		t.Fatalf("HashFiles() expected %d files, got %d", len(names), len(files))
+	}
+	for i, f := range files {
+		if f.Name() != dir+"/"+names[i] {
+			t.Errorf("HashFiles() expected order %s at %d, got %s", names[i], i, f.Name())
+		}
+	}
+
+	dups := findDuplicates(files)
+	if len(dups) != 1 || dups[files[0].Hash()] != 3 {
+		t.Errorf("findDuplicates() expected one digest with 3 files, got %v", dups)
+	}
+}
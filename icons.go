@@ -1,4 +1,4 @@
-package main
+package gtree
 
 import "github.com/gookit/color"
 
@@ -126,3 +126,112 @@ func NewIconString(suffix string) string {
 	}
 	return color.New(icon.Color).Sprint(icon.Icon)
 }
+
+// iconOf returns the icon of f.
+// When classify is true, the kind of f is preferred to the file type.
+func iconOf(f FileInfo, classify bool) Icon {
+	if f.IsDir() {
+		return defaultFolderIcon
+	}
+
+	if classify {
+		switch f.Kind() {
+		case kindBinary:
+			return binaryIcon
+		case kindExecutable:
+			return executableIcon
+		case kindGenerated:
+			return generatedIcon
+		}
+	}
+
+	if icon, ok := icons[f.FileType()]; ok {
+		return icon
+	}
+	return defaultFileIcon
+}
+
+var languages = map[string]string{
+	"styl":     "Stylus",
+	"sass":     "Sass",
+	"scss":     "SCSS",
+	"htm":      "HTML",
+	"html":     "HTML",
+	"slim":     "Slim",
+	"ejs":      "EJS",
+	"css":      "CSS",
+	"less":     "Less",
+	"md":       "Markdown",
+	"markdown": "Markdown",
+	"rmd":      "R Markdown",
+	"json":     "JSON",
+	"js":       "JavaScript",
+	"mjs":      "JavaScript",
+	"jsx":      "JavaScript",
+	"rb":       "Ruby",
+	"php":      "PHP",
+	"py":       "Python",
+	"coffee":   "CoffeeScript",
+	"mustache": "Mustache",
+	"hbs":      "Handlebars",
+	"ini":      "INI",
+	"yml":      "YAML",
+	"yaml":     "YAML",
+	"bat":      "Batchfile",
+	"twig":     "Twig",
+	"cpp":      "C++",
+	"cxx":      "C++",
+	"cc":       "C++",
+	"cp":       "C++",
+	"hpp":      "C++",
+	"hxx":      "C++",
+	"c":        "C",
+	"h":        "C",
+	"hs":       "Haskell",
+	"lhs":      "Haskell",
+	"lua":      "Lua",
+	"java":     "Java",
+	"sh":       "Shell",
+	"bash":     "Shell",
+	"zsh":      "Shell",
+	"ksh":      "Shell",
+	"csh":      "Shell",
+	"fish":     "Fish",
+	"awk":      "Awk",
+	"ps1":      "PowerShell",
+	"ml":       "OCaml",
+	"mli":      "OCaml",
+	"sql":      "SQL",
+	"clj":      "Clojure",
+	"cljc":     "Clojure",
+	"cljs":     "Clojure",
+	"edn":      "Clojure",
+	"scala":    "Scala",
+	"go":       "Go",
+	"dart":     "Dart",
+	"pl":       "Perl",
+	"pm":       "Perl",
+	"fs":       "F#",
+	"fsi":      "F#",
+	"fsx":      "F#",
+	"fsscript": "F#",
+	"rs":       "Rust",
+	"d":        "D",
+	"erl":      "Erlang",
+	"hrl":      "Erlang",
+	"ex":       "Elixir",
+	"exs":      "Elixir",
+	"eex":      "Elixir",
+	"vim":      "Vim script",
+	"ts":       "TypeScript",
+	"tsx":      "TypeScript",
+	"jl":       "Julia",
+	"pp":       "Puppet",
+	"vue":      "Vue",
+}
+
+// LanguageName returns the language name of the file extension.
+func LanguageName(suffix string) (string, bool) {
+	l, ok := languages[suffix]
+	return l, ok
+}
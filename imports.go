@@ -0,0 +1,304 @@
+package gtree
+
+import (
+	"bufio"
+	"go/build"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gookit/color"
+	"golang.org/x/xerrors"
+)
+
+// ImportsCommand is options for 'gtree imports' command.
+type ImportsCommand struct {
+	Std []bool `long:"std" description:"Expand the imports of the standard library packages."`
+}
+
+// IsStd returns true, if user specify '--std' option.
+func (i *ImportsCommand) IsStd() bool {
+	return len(i.Std) != 0
+}
+
+// Usage returns the usage of 'gtree imports' command.
+func (i *ImportsCommand) Usage() string {
+	return "[imports-OPTIONS] [<package directory list>]"
+}
+
+var (
+	stdColor        = color.Style{color.FgGray}
+	internalColor   = color.Style{color.FgLightBlue}
+	thirdPartyColor = color.Style{color.FgYellow}
+)
+
+// seeAbove is the annotation of the package whose imports are already listed.
+const seeAbove = "(see above)"
+
+// importGraph builds the import tree of Go packages.
+type importGraph struct {
+	mod *goModFile
+	cmd *ImportsCommand
+
+	// expanded is the packages whose imports are already listed.
+	expanded map[string]bool
+
+	// packages is the loaded packages keyed by the import path.
+	packages map[string]*build.Package
+
+	// modules is go.mod of the required modules keyed by the module path.
+	// It is nil, when the module has no go.mod.
+	modules map[string]*goModFile
+
+	annotations annotations
+}
+
+func newImportGraph(mod *goModFile, cmd *ImportsCommand) *importGraph {
+	return &importGraph{
+		mod:         mod,
+		cmd:         cmd,
+		expanded:    make(map[string]bool),
+		packages:    make(map[string]*build.Package),
+		modules:     make(map[string]*goModFile),
+		annotations: make(annotations),
+	}
+}
+
+// isStdPackage returns true, when the import path is in the standard library.
+// Like the go command, paths whose first element has no dot are the standard library.
+func isStdPackage(path string) bool {
+	first := path
+	if i := strings.Index(path, "/"); i >= 0 {
+		first = path[:i]
+	}
+	return !strings.Contains(first, ".")
+}
+
+// isInternal returns true, when the import path is in the main module.
+func (g *importGraph) isInternal(path string) bool {
+	return g.mod.provides(path)
+}
+
+// isStd returns true, when the import path is in the standard library.
+// The main module is checked first, since its path such as 'example' may have no dot.
+func (g *importGraph) isStd(path string) bool {
+	return !g.isInternal(path) && isStdPackage(path)
+}
+
+// resolveImport returns the import path of imp imported by the package of path.
+// Like the go command, the standard library packages import the packages vendored in GOROOT first,
+// and they are also the standard library such as 'vendor/golang.org/x/net/dns/dnsmessage'.
+func (g *importGraph) resolveImport(imp, path string) string {
+	if !g.isStd(path) || isStdPackage(imp) {
+		return imp
+	}
+
+	vendor := "vendor"
+	if path == "cmd" || strings.HasPrefix(path, "cmd/") {
+		vendor = "cmd/vendor"
+	}
+
+	vendored := vendor + "/" + imp
+	if info, err := os.Stat(filepath.Join(build.Default.GOROOT, "src", filepath.FromSlash(vendored))); err == nil && info.IsDir() {
+		return vendored
+	}
+	return imp
+}
+
+// requirer returns go.mod which requires the module of the import path.
+// The main module is preferred to from, which is go.mod of the importing package.
+func (g *importGraph) requirer(path string, from *goModFile) *goModFile {
+	if _, _, ok := g.mod.module(path); ok || from == nil {
+		return g.mod
+	}
+	if _, _, ok := from.module(path); ok || from.provides(path) {
+		return from
+	}
+	return g.mod
+}
+
+// moduleOf returns go.mod of the module which provides the import path.
+// For the module which has no go.mod, go.mod without requirements is returned.
+// It returns nil for the standard library.
+func (g *importGraph) moduleOf(path string, from *goModFile) *goModFile {
+	if g.isStd(path) {
+		return nil
+	}
+
+	req := g.requirer(path, from)
+	if req.provides(path) {
+		return req
+	}
+
+	modPath, _, ok := req.module(path)
+	if !ok {
+		return nil
+	}
+
+	if mod, ok := g.modules[modPath]; ok {
+		return mod
+	}
+
+	dir, err := req.packageDir(modPath)
+	if err != nil {
+		return nil
+	}
+
+	mod, err := readGoMod(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		mod = &goModFile{path: modPath, require: map[string]string{}, replace: map[string]goModReplace{}}
+	}
+	mod.dir = dir
+	g.modules[modPath] = mod
+	return mod
+}
+
+// load reads the package of the import path.
+// from is go.mod of the importing package.
+func (g *importGraph) load(path string, from *goModFile) (*build.Package, error) {
+	if pkg, ok := g.packages[path]; ok {
+		return pkg, nil
+	}
+
+	var dir string
+	if g.isStd(path) {
+		dir = filepath.Join(build.Default.GOROOT, "src", filepath.FromSlash(path))
+	} else {
+		d, err := g.requirer(path, from).packageDir(path)
+		if err != nil {
+			return nil, err
+		}
+		dir = d
+	}
+
+	pkg, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load %s: %w", path, err)
+	}
+	g.packages[path] = pkg
+	return pkg, nil
+}
+
+// importPath returns the import path of the package in dir of the main module.
+func (g *importGraph) importPath(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", xerrors.Errorf("failed to find package: %w", err)
+	}
+
+	rel, err := filepath.Rel(g.mod.dir, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", xerrors.Errorf("%s is not in the module %s", dir, g.mod.path)
+	}
+
+	if rel == "." {
+		return g.mod.path, nil
+	}
+	return g.mod.path + "/" + filepath.ToSlash(rel), nil
+}
+
+// tree returns the import tree of the package in dir.
+func (g *importGraph) tree(dir string) (*Node, error) {
+	path, err := g.importPath(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	root := NewNode(dir, "", true)
+	root.style = internalColor
+	g.annotations["."] = path
+	g.expand(root, path, ".", g.mod)
+	return root, nil
+}
+
+// expand adds the imports of the package to n.
+// rel is the path of n from the root of the tree, and from is go.mod of the importing package.
+func (g *importGraph) expand(n *Node, path, rel string, from *goModFile) {
+	pkg, err := g.load(path, from)
+	if err != nil {
+		n.SetError(err)
+		return
+	}
+	g.expanded[path] = true
+	mod := g.moduleOf(path, from)
+
+	imports := make([]string, 0, len(pkg.Imports))
+	for _, imp := range pkg.Imports {
+		// "C" is the pseudo package of cgo.
+		if imp != "C" {
+			imports = append(imports, g.resolveImport(imp, path))
+		}
+	}
+	sort.Strings(imports)
+	for _, imp := range imports {
+
+		child := n.Add(NewNode(imp, "go", false))
+		childRel := joinRel(rel, imp)
+
+		switch {
+		case g.isInternal(imp):
+			child.style = internalColor
+		case g.isStd(imp):
+			child.style = stdColor
+		default:
+			child.style = thirdPartyColor
+			if modPath, version, ok := g.requirer(imp, mod).module(imp); ok && modPath == imp {
+				g.annotations[childRel] = version
+			} else if ok {
+				g.annotations[childRel] = modPath + " " + version
+			}
+		}
+
+		if g.isStd(imp) && !g.cmd.IsStd() {
+			continue
+		}
+
+		if g.expanded[imp] {
+			if a, ok := g.annotations[childRel]; ok {
+				g.annotations[childRel] = a + " " + seeAbove
+			} else {
+				g.annotations[childRel] = seeAbove
+			}
+			continue
+		}
+
+		g.expand(child, imp, childRel, mod)
+		child.isDir = len(child.children) != 0 || child.err != nil
+	}
+}
+
+// showImportTrees writes the import trees of the packages in roots.
+func showImportTrees(out io.Writer, roots []string, opts Options) error {
+	w := bufio.NewWriter(out)
+	// The trees written before an error are shown. The error of flushing is returned at the end.
+	defer w.Flush()
+
+	displayOptions := opts.ListOptions.ListDisplayOptions
+	tw, err := newTreeWriter(w, displayOptions, newReport(displayOptions))
+	if err != nil {
+		return err
+	}
+
+	for _, root := range roots {
+		mod, err := findModule(root)
+		if err != nil {
+			return err
+		}
+
+		g := newImportGraph(mod, opts.ImportsCommand)
+		tree, err := g.tree(root)
+		if err != nil {
+			return err
+		}
+
+		if err := tw.writeTree(NodeTree(tree, opts.ListOptions.ListSearchOptions), mergeAnnotations(g.annotations, displayOptions.annotations)); err != nil {
+			return err
+		}
+	}
+	if err := tw.close(); err != nil {
+		return err
+	}
+	return flushOutput(w)
+}
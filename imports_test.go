@@ -0,0 +1,176 @@
+package gtree
+
+import (
+	"go/build"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestImportGraph(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gtree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	defer os.Setenv("GOMODCACHE", os.Getenv("GOMODCACHE"))
+	os.Setenv("GOMODCACHE", filepath.Join(dir, "cache"))
+
+	writeTestFiles(t, dir, map[string]string{
+		"m/go.mod":         "module example.com/m\n\nrequire example.com/dep v1.0.0\n",
+		"m/cmd/foo/foo.go": "package main\n\nimport (\n\t\"fmt\"\n\t\"example.com/m/lib\"\n\t\"example.com/m/util\"\n)\n",
+		"m/lib/lib.go":     "package lib\n\nimport (\n\t\"example.com/dep/a\"\n\t\"example.com/m/util\"\n)\n",
+		"m/util/util.go":   "package util\n\nimport \"strings\"\n",
+
+		"cache/example.com/dep@v1.0.0/go.mod": "module example.com/dep\n\nrequire example.com/indirect v0.1.0\n",
+		"cache/example.com/dep@v1.0.0/a/a.go": "package a\n\nimport (\n\t\"example.com/dep/b\"\n\t\"example.com/indirect\"\n)\n",
+		"cache/example.com/dep@v1.0.0/b/b.go": "package b\n",
+
+		"cache/example.com/indirect@v0.1.0/indirect.go": "package indirect\n",
+	})
+
+	root := filepath.Join(dir, "m", "cmd", "foo")
+	mod, err := findModule(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := importLines(t, mod, root, &ImportsCommand{})
+	expect := []string{
+		". # example.com/m/cmd/foo",
+		"example.com/m/lib",
+		"example.com/m/lib/example.com/dep/a # example.com/dep v1.0.0",
+		"example.com/m/lib/example.com/dep/a/example.com/dep/b # example.com/dep v1.0.0",
+		"example.com/m/lib/example.com/dep/a/example.com/indirect # v0.1.0",
+		"example.com/m/lib/example.com/m/util",
+		"example.com/m/lib/example.com/m/util/strings",
+		"example.com/m/util # (see above)",
+		"fmt",
+	}
+	if strings.Join(lines, "\n") != strings.Join(expect, "\n") {
+		t.Errorf("importGraph.tree() expected\n%s\ngot\n%s", strings.Join(expect, "\n"), strings.Join(lines, "\n"))
+	}
+}
+
+func TestImportGraph_DotlessModule(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gtree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeTestFiles(t, dir, map[string]string{
+		"go.mod":         "module example\n",
+		"cmd/foo/foo.go": "package main\n\nimport (\n\t\"fmt\"\n\t\"example/util\"\n)\n",
+		"util/util.go":   "package util\n\nimport \"strings\"\n",
+	})
+
+	root := filepath.Join(dir, "cmd", "foo")
+	mod, err := findModule(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := importLines(t, mod, root, &ImportsCommand{})
+	expect := []string{
+		". # example/cmd/foo",
+		"example/util",
+		"example/util/strings",
+		"fmt",
+	}
+	if strings.Join(lines, "\n") != strings.Join(expect, "\n") {
+		t.Errorf("importGraph.tree() expected\n%s\ngot\n%s", strings.Join(expect, "\n"), strings.Join(lines, "\n"))
+	}
+}
+
+func TestImportGraph_VendoredStd(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gtree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	defer func(goroot string) { build.Default.GOROOT = goroot }(build.Default.GOROOT)
+	build.Default.GOROOT = filepath.Join(dir, "goroot")
+
+	writeTestFiles(t, dir, map[string]string{
+		"m/go.mod":  "module example.com/m\n",
+		"m/main.go": "package main\n\nimport \"net\"\n",
+
+		"goroot/src/net/net.go": "package net\n\nimport \"golang.org/x/net/dns/dnsmessage\"\n",
+		"goroot/src/vendor/golang.org/x/net/dns/dnsmessage/message.go": "package dnsmessage\n\nimport \"golang.org/x/net/idna\"\n",
+		"goroot/src/vendor/golang.org/x/net/idna/idna.go":              "package idna\n",
+	})
+
+	root := filepath.Join(dir, "m")
+	mod, err := findModule(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The vendored packages are loaded from GOROOT as the standard library, and have no versions.
+	lines := importLines(t, mod, root, &ImportsCommand{Std: []bool{true}})
+	expect := []string{
+		". # example.com/m",
+		"net",
+		"net/vendor/golang.org/x/net/dns/dnsmessage",
+		"net/vendor/golang.org/x/net/dns/dnsmessage/vendor/golang.org/x/net/idna",
+	}
+	if strings.Join(lines, "\n") != strings.Join(expect, "\n") {
+		t.Errorf("importGraph.tree() expected\n%s\ngot\n%s", strings.Join(expect, "\n"), strings.Join(lines, "\n"))
+	}
+}
+
+// importLines returns the relative paths of the import tree of the package in root with annotations and errors.
+func importLines(t *testing.T, mod *goModFile, root string, cmd *ImportsCommand) []string {
+	g := newImportGraph(mod, cmd)
+	tree, err := g.tree(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := make([]string, 0)
+	ch := make(chan FileInfo)
+	go nodeWalk(tree, ch, &ListSearchOptions{})
+	for f := range ch {
+		line := relativePath(f)
+		if a, ok := g.annotations.lookup(f); ok {
+			line += " # " + a
+		}
+		if f.Error() != nil {
+			line += " [" + f.Error().Error() + "]"
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestIsStdPackage(t *testing.T) {
+	tests := map[string]bool{
+		"fmt":                  true,
+		"net/http":             true,
+		"golang.org/x/xerrors": false,
+		"example.com":          false,
+	}
+
+	for path, expect := range tests {
+		if got := isStdPackage(path); got != expect {
+			t.Errorf("isStdPackage(%s) expected %v, got %v", path, expect, got)
+		}
+	}
+}
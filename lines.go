@@ -0,0 +1,158 @@
+package gtree
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sort"
+)
+
+// linesWidth is the width of the lines column.
+const linesWidth = 7
+
+// sniffLength is the length of the head of file used to detect binary files.
+const sniffLength = 8000
+
+// countLines returns the number of lines in the file.
+// When the file looks binary, it returns false.
+func countLines(path string) (int, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, false, err
+	}
+	defer f.Close()
+
+	// The head is read at once, so that binary files are detected in the same way as classifyFile.
+	buf := make([]byte, 32*1024)
+	n, err := io.ReadFull(f, buf[:sniffLength])
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return 0, false, err
+	}
+	if isBinary(buf[:n]) {
+		return 0, false, nil
+	}
+
+	lines := 0
+	last := byte('\n')
+	for {
+		if n > 0 {
+			lines += bytes.Count(buf[:n], []byte{'\n'})
+			last = buf[n-1]
+		}
+
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return 0, false, err
+		}
+		n, err = f.Read(buf)
+	}
+
+	// The last line without a line break.
+	if last != '\n' {
+		lines++
+	}
+	return lines, true, nil
+}
+
+// CountLines counts the lines of every regular file received from in
+// with a pool of workers, and sends the files to out in the received order.
+// Binary files aren't counted.
+// It closes out when in is closed.
+func CountLines(in <-chan FileInfo, out chan<- FileInfo) {
+	processFiles(in, out, func(f FileInfo) {
+		lines, ok, err := countLines(f.Path())
+		if err != nil {
+			f.SetError(err)
+		} else if ok {
+			f.SetLines(lines)
+		}
+	})
+}
+
+// arrangeLines rolls up lines into directories and sorts files by '--lines' and '--sort' options.
+func arrangeLines(files []FileInfo, opts *ListDisplayOptions) []FileInfo {
+	if !opts.IsLines() {
+		return files
+	}
+
+	rollUpLines(files)
+	if opts.Sort == "lines" {
+		return sortByLines(files)
+	}
+	return files
+}
+
+// rollUpLines sets the total lines of descendants to each directory in files.
+func rollUpLines(files []FileInfo) {
+	totals := make(map[FileInfo]int)
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+
+		lines, ok := f.Lines()
+		if !ok {
+			continue
+		}
+
+		for p, ok := f.Parent(); ok; p, ok = p.Parent() {
+			totals[p] += lines
+		}
+	}
+
+	for _, f := range files {
+		if f.IsDir() {
+			f.SetLines(totals[f])
+		}
+	}
+}
+
+// sortByLines reorders siblings in files by their lines in descending order,
+// keeping the order of the tree that directories precede their descendants.
+// Files whose lines aren't counted follow counted siblings.
+func sortByLines(files []FileInfo) []FileInfo {
+	children := make(map[FileInfo][]FileInfo)
+	roots := make([]FileInfo, 0)
+	for _, f := range files {
+		if p, ok := f.Parent(); ok {
+			children[p] = append(children[p], f)
+		} else {
+			roots = append(roots, f)
+		}
+	}
+
+	result := make([]FileInfo, 0, len(files))
+	var visit func(f FileInfo)
+	visit = func(f FileInfo) {
+		result = append(result, f)
+
+		siblings := children[f]
+		sort.SliceStable(siblings, func(i, j int) bool {
+			// The placeholder of omitted files stays at the end.
+			if IsOmitted(siblings[i]) || IsOmitted(siblings[j]) {
+				return IsOmitted(siblings[j]) && !IsOmitted(siblings[i])
+			}
+
+			li, oki := siblings[i].Lines()
+			lj, okj := siblings[j].Lines()
+			if oki != okj {
+				return oki
+			}
+			return li > lj
+		})
+
+		for i, c := range siblings {
+			if l, ok := c.(interface{ setLast(bool) }); ok {
+				l.setLast(i == len(siblings)-1)
+			}
+			visit(c)
+		}
+	}
+
+	for _, r := range roots {
+		visit(r)
+	}
+	return result
+}
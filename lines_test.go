@@ -0,0 +1,93 @@
+package gtree
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCountLines(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gtree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := map[string]struct {
+		content string
+		lines   int
+		text    bool
+	}{
+		"empty":             {"", 0, true},
+		"one line":          {"hello\n", 1, true},
+		"no last line feed": {"hello\nworld", 2, true},
+		"binary":            {"\x00\x01\x02\n", 0, false},
+		"image":             {"\x89PNG\r\n\x1a\n", 0, false},
+		"long":              {strings.Repeat("line\n", 10000), 10000, true},
+	}
+
+	for key, tt := range tests {
+		t.Run(key, func(t *testing.T) {
+			path := filepath.Join(dir, key)
+			if err := ioutil.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			lines, text, err := countLines(path)
+			if err != nil {
+				t.Fatalf("countLines() unexpected error: %v", err)
+			}
+			if lines != tt.lines || text != tt.text {
+				t.Errorf("countLines() expected (%d, %v), got (%d, %v)", tt.lines, tt.text, lines, text)
+			}
+		})
+	}
+}
+
+func TestArrangeLines(t *testing.T) {
+	root := newDummyPrinterFileInfo("root", "root", "", "", true, true, nil, nil)
+	small := newDummyPrinterFileInfo("small", "root/small", "", "", false, true, nil, root)
+	a := newDummyPrinterFileInfo("a.go", "root/small/a.go", "go", "", true, false, nil, small)
+	large := newDummyPrinterFileInfo("large", "root/large", "", "", false, true, nil, root)
+	b := newDummyPrinterFileInfo("b.go", "root/large/b.go", "go", "", false, false, nil, large)
+	c := newDummyPrinterFileInfo("c.go", "root/large/c.go", "go", "", true, false, nil, large)
+	bin := newDummyPrinterFileInfo("bin", "root/bin", "", "", true, false, nil, root)
+	a.SetLines(10)
+	b.SetLines(20)
+	c.SetLines(30)
+
+	files := arrangeLines([]FileInfo{root, small, a, large, b, c, bin}, &ListDisplayOptions{Lines: []bool{true}, Sort: "lines"})
+
+	expect := []string{"root", "root/large", "root/large/c.go", "root/large/b.go", "root/small", "root/small/a.go", "root/bin"}
+	for i, f := range files {
+		if f.Path() != expect[i] {
+			t.Errorf("%d: arrangeLines() expected '%s', got '%s'", i, expect[i], f.Path())
+		}
+	}
+
+	for _, tt := range []struct {
+		file  FileInfo
+		lines int
+	}{{root, 60}, {small, 10}, {large, 50}} {
+		if lines, ok := tt.file.Lines(); !ok || lines != tt.lines {
+			t.Errorf("%s: Lines() expected %d, got %d", tt.file.Path(), tt.lines, lines)
+		}
+	}
+}
+
+func TestSortByLines_IsLast(t *testing.T) {
+	rootInfo := newDummyOsFile("root", true)
+	root := NewFileInfo(rootInfo, nil, false)
+	a := NewFileInfo(newDummyOsFile("a", false), root, false)
+	b := NewFileInfo(newDummyOsFile("b", false), root, true)
+	a.SetLines(1)
+	b.SetLines(2)
+
+	sortByLines([]FileInfo{root, a, b})
+
+	if !a.IsLast() || b.IsLast() {
+		t.Errorf("sortByLines() expected a is last and b isn't last, got %v and %v", a.IsLast(), b.IsLast())
+	}
+}
@@ -0,0 +1,200 @@
+package gtree
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	"*", `\*`,
+	"_", `\_`,
+	"[", `\[`,
+	"]", `\]`,
+	"<", `\<`,
+	">", `\>`,
+)
+
+// MarkdownPrinter writes FileInfo as markdown without colors and icons.
+// The tree is written in a fenced code block, or as a nested bullet list.
+type MarkdownPrinter struct {
+	*Printer
+
+	// code is the lines of the code block, which are written at the end,
+	// since the fence must be longer than backticks in file names.
+	code bytes.Buffer
+}
+
+// NewMarkdownPrinter return MarkdownPrinter pointer.
+func NewMarkdownPrinter(opt *ListDisplayOptions) *MarkdownPrinter {
+	return &MarkdownPrinter{
+		Printer: NewPrinter(opt),
+	}
+}
+
+func (p *MarkdownPrinter) isList() bool {
+	return p.opt.MarkdownStyle == "list"
+}
+
+// Begin resets the lines of the code block, since the fence is written at the end.
+func (p *MarkdownPrinter) Begin(w io.Writer) error {
+	p.code.Reset()
+	return nil
+}
+
+// EnterDir writes the line of the directory.
+func (p *MarkdownPrinter) EnterDir(w io.Writer, e *Entry) error {
+	return p.Write(w, e.FileInfo)
+}
+
+// Entry writes the line of the file.
+func (p *MarkdownPrinter) Entry(w io.Writer, e *Entry) error {
+	return p.Write(w, e.FileInfo)
+}
+
+// End writes the lines of the code block in the fence, and the report and the statistics after it.
+func (p *MarkdownPrinter) End(w io.Writer, r *Report) error {
+	if !p.isList() {
+		fence := codeFence(p.code.String())
+		_, err := fmt.Fprintf(w, "%s\n%s%s\n", fence, p.code.String(), fence)
+		if err != nil {
+			return xerrors.Errorf("failed to write: %w", err)
+		}
+	}
+	return writeSummary(w, r, p.opt)
+}
+
+// codeFence returns the fence which is longer than any run of backticks in code.
+func codeFence(code string) string {
+	longest, run := 0, 0
+	for _, c := range code {
+		if c != '`' {
+			run = 0
+			continue
+		}
+		run++
+		if run > longest {
+			longest = run
+		}
+	}
+
+	if longest < 3 {
+		return "```"
+	}
+	return strings.Repeat("`", longest+1)
+}
+
+func (p *MarkdownPrinter) Write(w io.Writer, f FileInfo) error {
+	entry, err := p.entry(f)
+	if err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+	b.WriteString(entry)
+	if d, ok := p.annotations.lookup(f); ok && p.isList() {
+		b.WriteString(" — " + d)
+	} else {
+		p.writeAnnotation(&b, f, entry)
+	}
+	b.WriteString("\n")
+
+	if !p.isList() {
+		p.code.Write(b.Bytes())
+		return nil
+	}
+
+	_, err = w.Write(b.Bytes())
+	if err != nil {
+		return xerrors.Errorf("failed to write: %w", err)
+	}
+	return nil
+}
+
+// SetAnnotations sets descriptions of files.
+// The descriptions of siblings in the code block are aligned.
+func (p *MarkdownPrinter) SetAnnotations(a annotations, files []FileInfo) {
+	p.annotations = a
+	p.columns = annotationColumns(files, func(f FileInfo) int {
+		entry, err := p.entry(f)
+		if err != nil {
+			return 0
+		}
+		return p.opt.displayWidth(entry)
+	})
+}
+
+// entry returns the line of f without the annotation and the line break.
+func (p *MarkdownPrinter) entry(f FileInfo) (string, error) {
+	var b strings.Builder
+
+	if p.isList() {
+		b.WriteString(strings.Repeat("  ", depthOf(f)) + "- ")
+		b.WriteString(p.listName(f))
+	} else {
+		if pa, ok := f.Parent(); ok {
+			if err := p.writePrefix(&b, f, p.childPrefix(pa)); err != nil {
+				return "", xerrors.Errorf("failed to writePrefix: %w", err)
+			}
+		}
+		if err := p.writeMeta(&b, f); err != nil {
+			return "", xerrors.Errorf("failed to writeMeta: %w", err)
+		}
+		b.WriteString(p.writtenName(f))
+	}
+
+	if f.IsSym() {
+		symLink, err := f.SymLink()
+		if err != nil {
+			return "", xerrors.Errorf("failed to retrieve symlink path: %w", err)
+		}
+		fmt.Fprintf(&b, " -> %s", p.opt.safeName(symLink))
+	}
+
+	if err := f.Error(); err != nil {
+		fmt.Fprintf(&b, " [%s]", p.opt.escape(err.Error(), false))
+	}
+	return b.String(), nil
+}
+
+func (p *MarkdownPrinter) writtenName(f FileInfo) string {
+	if p.opt.IsFullPath() {
+		return p.opt.safeName(labelOf(f, f.Path()))
+	}
+	return p.opt.safeName(labelOf(f, f.Name()))
+}
+
+// listName returns the name of the bullet list item, which is a relative link if user specify '--markdown-links'.
+func (p *MarkdownPrinter) listName(f FileInfo) string {
+	name := markdownEscaper.Replace(p.writtenName(f))
+	if f.IsDir() {
+		name += "/"
+	}
+
+	if !p.opt.IsMarkdownLinks() || IsOmitted(f) {
+		return name
+	}
+
+	link := relativePath(f)
+	if link == "." {
+		link = "./"
+	} else if f.IsDir() {
+		link += "/"
+	}
+	return fmt.Sprintf("[%s](%s)", name, (&url.URL{Path: link}).String())
+}
+
+// depthOf returns the depth from the tree's root.
+func depthOf(f FileInfo) int {
+	depth := 0
+	for p, ok := f.Parent(); ok; p, ok = p.Parent() {
+		depth++
+	}
+	return depth
+}
@@ -0,0 +1,91 @@
+package gtree
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMarkdownPrinter(t *testing.T) {
+	root := newDummyPrinterFileInfo("root", "root", "", "", true, true, nil, nil)
+	dir := newDummyPrinterFileInfo("my dir", "root/my dir", "", "", false, true, nil, root)
+	file := newDummyPrinterFileInfo("main_test.go", "root/my dir/main_test.go", "go", "", true, false, nil, dir)
+	link := newDummyPrinterFileInfo("link", "root/link", "", "/target", true, false, nil, root)
+	files := []FileInfo{root, dir, file, link}
+
+	a := annotations{
+		".":      "the root",
+		"my dir": "a directory",
+	}
+
+	tests := map[string]struct {
+		displayOption *ListDisplayOptions
+		output        string
+	}{
+		"code block": {
+			displayOption: &ListDisplayOptions{MarkdownStyle: "code"},
+			output: "```\n" +
+				"root  # the root\n" +
+				"├── my dir           # a directory\n" +
+				"│   └── main_test.go\n" +
+				"└── link -> /target\n" +
+				"```\n",
+		},
+		"bullet list": {
+			displayOption: &ListDisplayOptions{MarkdownStyle: "list"},
+			output: "- root/ — the root\n" +
+				"  - my dir/ — a directory\n" +
+				"    - main\\_test.go\n" +
+				"  - link -> /target\n",
+		},
+		"bullet list with links": {
+			displayOption: &ListDisplayOptions{MarkdownStyle: "list", MarkdownLinks: []bool{true}},
+			output: "- [root/](./) — the root\n" +
+				"  - [my dir/](my%20dir/) — a directory\n" +
+				"    - [main\\_test.go](my%20dir/main_test.go)\n" +
+				"  - [link](link) -> /target\n",
+		},
+	}
+
+	for key, tt := range tests {
+		t.Run(key, func(t *testing.T) {
+			p := NewMarkdownPrinter(tt.displayOption)
+			p.SetAnnotations(a, files)
+
+			buffer := new(bytes.Buffer)
+			p.Begin(buffer)
+			for _, f := range files {
+				if err := p.Write(buffer, f); err != nil {
+					t.Fatalf("printer.Write() returns error: %v", err)
+				}
+			}
+			p.End(buffer, &Report{})
+
+			if buffer.String() != tt.output {
+				t.Errorf("MarkdownPrinter expected '%s', got '%s'", tt.output, buffer.String())
+			}
+		})
+	}
+}
+
+func TestMarkdownPrinter_Fence(t *testing.T) {
+	root := newDummyPrinterFileInfo("root", "root", "", "", true, true, nil, nil)
+	file := newDummyPrinterFileInfo("a````b.md", "root/a````b.md", "md", "", true, false, nil, root)
+
+	p := NewMarkdownPrinter(&ListDisplayOptions{MarkdownStyle: "code"})
+	buffer := new(bytes.Buffer)
+	p.Begin(buffer)
+	for _, f := range []FileInfo{root, file} {
+		if err := p.Write(buffer, f); err != nil {
+			t.Fatalf("printer.Write() returns error: %v", err)
+		}
+	}
+	p.End(buffer, &Report{})
+
+	expect := "`````\n" +
+		"root\n" +
+		"└── a````b.md\n" +
+		"`````\n"
+	if buffer.String() != expect {
+		t.Errorf("MarkdownPrinter expected '%s', got '%s'", expect, buffer.String())
+	}
+}
@@ -0,0 +1,84 @@
+package gtree
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// isPrintableRune returns true, if r is displayed as a character.
+// Zero width joiners are printable, since they combine emoji sequences.
+func isPrintableRune(r rune) bool {
+	return unicode.IsGraphic(r) || r == '\u200c' || r == '\u200d'
+}
+
+// escapeRune returns the escape sequence of the non-printable rune such as '\n' and '\x1b'.
+func escapeRune(r rune) string {
+	switch r {
+	case '\a':
+		return `\a`
+	case '\b':
+		return `\b`
+	case '\t':
+		return `\t`
+	case '\n':
+		return `\n`
+	case '\v':
+		return `\v`
+	case '\f':
+		return `\f`
+	case '\r':
+		return `\r`
+	}
+
+	switch {
+	case r < 0x100:
+		return fmt.Sprintf(`\x%02x`, r)
+	case r <= 0xffff:
+		return fmt.Sprintf(`\u%04x`, r)
+	default:
+		return fmt.Sprintf(`\U%08x`, r)
+	}
+}
+
+// safeName returns name which is safe to print, and is quoted by '--quote' option.
+func (l *ListDisplayOptions) safeName(name string) string {
+	if l.IsQuote() {
+		return `"` + l.escape(name, true) + `"`
+	}
+	return l.escape(name, false)
+}
+
+// escape returns s whose non-printable characters and invalid UTF-8 bytes are
+// escaped such as '\n' and '\x1b', replaced with '?' by '-q' option, or kept as is by '-N' option.
+// So that file names and messages can't forge tree lines, or control the terminal.
+// When quote is true, double quotes and backslashes are also escaped.
+func (l *ListDisplayOptions) escape(s string, quote bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case quote && (r == '"' || r == '\\'):
+			b.WriteString(`\` + string(r))
+		case l.IsLiteral():
+			b.WriteString(s[i : i+size])
+		case r == utf8.RuneError && size == 1:
+			if l.IsQuestionMark() {
+				b.WriteByte('?')
+			} else {
+				fmt.Fprintf(&b, `\x%02x`, s[i])
+			}
+		case !isPrintableRune(r):
+			if l.IsQuestionMark() {
+				b.WriteByte('?')
+			} else {
+				b.WriteString(escapeRune(r))
+			}
+		default:
+			b.WriteRune(r)
+		}
+		i += size
+	}
+	return b.String()
+}
@@ -0,0 +1,33 @@
+package gtree
+
+import "testing"
+
+func TestSafeName(t *testing.T) {
+	tests := map[string]struct {
+		name   string
+		opt    *ListDisplayOptions
+		output string
+	}{
+		"printable":                {"日本語 file.txt", &ListDisplayOptions{}, "日本語 file.txt"},
+		"newline":                  {"a\nb", &ListDisplayOptions{}, `a\nb`},
+		"escape sequence":          {"\x1b[31mred", &ListDisplayOptions{}, `\x1b[31mred`},
+		"invalid utf8":             {"bad\xff", &ListDisplayOptions{}, `bad\xff`},
+		"c1 control":               {"a\u0085b", &ListDisplayOptions{}, `a\x85b`},
+		"bidi override":            {"a\u202eb", &ListDisplayOptions{}, `a\u202eb`},
+		"zero width joiner":        {"👩\u200d💻", &ListDisplayOptions{}, "👩\u200d💻"},
+		"backslash":                {`a\b`, &ListDisplayOptions{}, `a\b`},
+		"question mark":            {"a\n\x1b\xffb", &ListDisplayOptions{QuestionMark: []bool{true}}, "a???b"},
+		"literal":                  {"a\n\x1bb", &ListDisplayOptions{Literal: []bool{true}}, "a\n\x1bb"},
+		"quote":                    {`say "hi"\` + "\n", &ListDisplayOptions{Quote: []bool{true}}, `"say \"hi\"\\\n"`},
+		"quote with literal":       {"\"a\n", &ListDisplayOptions{Quote: []bool{true}, Literal: []bool{true}}, "\"\\\"a\n\""},
+		"quote with question mark": {"\"a\n", &ListDisplayOptions{Quote: []bool{true}, QuestionMark: []bool{true}}, `"\"a?"`},
+	}
+
+	for key, tt := range tests {
+		t.Run(key, func(t *testing.T) {
+			if got := tt.opt.safeName(tt.name); got != tt.output {
+				t.Errorf("safeName(%q) expected %q, got %q", tt.name, tt.output, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package gtree
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// ndjsonRecord is the line of a file in NDJSON.
+type ndjsonRecord struct {
+	Path       string `json:"path"`
+	Name       string `json:"name"`
+	Depth      int    `json:"depth"`
+	Parent     string `json:"parent,omitempty"`
+	Type       string `json:"type"`
+	Size       *int64 `json:"size,omitempty"`
+	Mode       string `json:"mode,omitempty"`
+	ModTime    string `json:"mtime,omitempty"`
+	Target     string `json:"target,omitempty"`
+	Hash       string `json:"hash,omitempty"`
+	Lines      *int   `json:"lines,omitempty"`
+	Annotation string `json:"annotation,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// NDJSONFormatter writes each file as a JSON object in a line as soon as it is received.
+// Since nothing is kept except the directories being written, huge trees are written with constant memory.
+type NDJSONFormatter struct {
+	enc *json.Encoder
+}
+
+// NewNDJSONFormatter returns NDJSONFormatter pointer.
+func NewNDJSONFormatter() *NDJSONFormatter {
+	return &NDJSONFormatter{}
+}
+
+// Begin prepares the encoder, since NDJSON has no header.
+func (n *NDJSONFormatter) Begin(w io.Writer) error {
+	n.enc = json.NewEncoder(w)
+	n.enc.SetEscapeHTML(false)
+	return nil
+}
+
+// EnterDir writes the line of the directory.
+func (n *NDJSONFormatter) EnterDir(w io.Writer, e *Entry) error {
+	return n.Entry(w, e)
+}
+
+// Entry writes the line of the file.
+// The placeholders of '--head' option are skipped, since they aren't files.
+func (n *NDJSONFormatter) Entry(w io.Writer, e *Entry) error {
+	if IsOmitted(e.FileInfo) {
+		return nil
+	}
+
+	if err := n.enc.Encode(newNDJSONRecord(e)); err != nil {
+		return xerrors.Errorf("failed to write: %w", err)
+	}
+	return nil
+}
+
+// LeaveDir does nothing, since the lines of descendants are already written.
+func (n *NDJSONFormatter) LeaveDir(w io.Writer, e *Entry) error {
+	return nil
+}
+
+// End does nothing, since NDJSON has no footer.
+func (n *NDJSONFormatter) End(w io.Writer, r *Report) error {
+	return nil
+}
+
+func newNDJSONRecord(e *Entry) *ndjsonRecord {
+	f := e.FileInfo
+	record := &ndjsonRecord{
+		Path:       f.Path(),
+		Name:       labelOf(f, f.Name()),
+		Depth:      e.Depth,
+		Hash:       f.Hash(),
+		Annotation: e.Annotation,
+	}
+
+	if p, ok := f.Parent(); ok {
+		record.Parent = p.Path()
+	}
+
+	info, ok := statOf(f)
+	record.Type = typeName(f, info, ok)
+	if ok {
+		size := info.Size()
+		record.Size = &size
+		record.Mode = info.Mode().String()
+		record.ModTime = info.ModTime().Format(time.RFC3339)
+	}
+
+	if f.IsSym() {
+		if target, err := f.SymLink(); err == nil {
+			record.Target = target
+		}
+	}
+
+	if lines, ok := f.Lines(); ok {
+		record.Lines = &lines
+	}
+
+	if err := f.Error(); err != nil {
+		record.Error = err.Error()
+	}
+	return record
+}
+
+// statOf returns the metadata of f such as the size and the mode.
+// The files on the disk and the entries of archives have the metadata.
+func statOf(f FileInfo) (os.FileInfo, bool) {
+	if info, ok := osFileInfo(f); ok {
+		return info, true
+	}
+	if n, ok := f.(*Node); ok && n.info != nil {
+		return n.info, true
+	}
+	return nil, false
+}
+
+// typeName returns the type of f such as 'file' and 'directory'.
+func typeName(f FileInfo, info os.FileInfo, ok bool) string {
+	if ok {
+		mode := info.Mode()
+		switch {
+		case mode&os.ModeSymlink != 0:
+			return "symlink"
+		case mode.IsDir():
+			return "directory"
+		case mode&os.ModeNamedPipe != 0:
+			return "pipe"
+		case mode&os.ModeSocket != 0:
+			return "socket"
+		case mode&os.ModeDevice != 0:
+			return "device"
+		}
+	}
+
+	switch {
+	case f.IsSym():
+		return "symlink"
+	case f.IsDir():
+		return "directory"
+	default:
+		return "file"
+	}
+}
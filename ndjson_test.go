@@ -0,0 +1,93 @@
+package gtree
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNDJSONFormatter(t *testing.T) {
+	level := 1
+	opts := Options{ListOptions: &ListOptions{
+		ListSearchOptions:  &ListSearchOptions{Level: &level},
+		ListDisplayOptions: &ListDisplayOptions{Format: "ndjson"},
+	}}
+
+	buffer := new(bytes.Buffer)
+	if err := printTree(buffer, NodeTree(newTestNodes(), opts.ListOptions.ListSearchOptions), opts, &Report{}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"path":"root","name":"root","depth":0,"type":"directory"}
+{"path":"root/a","name":"a","depth":1,"parent":"root","type":"directory"}
+{"path":"root/e","name":"e","depth":1,"parent":"root","type":"directory"}
+{"path":"root/f.go","name":"f.go","depth":1,"parent":"root","type":"file"}
+`
+	if buffer.String() != expected {
+		t.Errorf("expected\n%s\nbut got\n%s", expected, buffer.String())
+	}
+}
+
+func TestNDJSONFormatter_Disk(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gtree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeTestFiles(t, dir, map[string]string{"a.txt": "hello"})
+	if err := os.Symlink("a.txt", filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	rootFile, err := newRootFileInfo(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{ListOptions: &ListOptions{
+		ListSearchOptions:  &ListSearchOptions{},
+		ListDisplayOptions: &ListDisplayOptions{Format: "ndjson"},
+	}}
+
+	buffer := new(bytes.Buffer)
+	if err := printTree(buffer, walkTree(rootFile, opts), opts, &Report{}); err != nil {
+		t.Fatal(err)
+	}
+
+	records := make(map[string]ndjsonRecord)
+	scanner := bufio.NewScanner(buffer)
+	for scanner.Scan() {
+		var record ndjsonRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("invalid line %q: %v", scanner.Text(), err)
+		}
+		records[record.Name] = record
+	}
+
+	tests := map[string]struct {
+		typ    string
+		mode   string
+		target string
+	}{
+		"a.txt": {"file", "-", ""},
+		"link":  {"symlink", "L", "a.txt"},
+	}
+	for name, tt := range tests {
+		record, ok := records[name]
+		if !ok {
+			t.Errorf("%s isn't written", name)
+			continue
+		}
+		if record.Type != tt.typ || !strings.HasPrefix(record.Mode, tt.mode) || record.Target != tt.target || record.Parent != dir || record.Depth != 1 {
+			t.Errorf("%s: unexpected record %+v", name, record)
+		}
+		if tt.typ == "file" && (record.Size == nil || *record.Size != 5 || record.ModTime == "") {
+			t.Errorf("%s: expected size and mtime, got %+v", name, record)
+		}
+	}
+}
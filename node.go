@@ -0,0 +1,241 @@
+package gtree
+
+import (
+	"os"
+	"strings"
+
+	"github.com/gookit/color"
+	"golang.org/x/xerrors"
+)
+
+// Node is a FileInfo which doesn't exist on the disk, such as a Go package or a key of a document.
+// The children are built in memory before the tree is sent by nodeWalk.
+type Node struct {
+	name     string
+	fileType string
+	isDir    bool
+	parent   FileInfo
+	isLast   bool
+	children []*Node
+
+	// info is the metadata of the node such as an archive entry, which is used by predicates.
+	info os.FileInfo
+
+	// value is the scalar of a document shown after the name such as 'key: value'.
+	value string
+
+	// style is the color of the name given by the source of nodes.
+	style color.Style
+
+	err     error
+	hash    string
+	lines   int
+	counted bool
+	kind    FileKind
+}
+
+var _ FileInfo = (*Node)(nil)
+
+// NewNode returns the node of the in-memory tree.
+// fileType is the extension which decides the icon such as "go", and it is "" for directories.
+func NewNode(name, fileType string, isDir bool) *Node {
+	return &Node{
+		name:     name,
+		fileType: fileType,
+		isDir:    isDir,
+	}
+}
+
+// Add appends child to the children of n, and returns child.
+func (n *Node) Add(child *Node) *Node {
+	child.parent = n
+	n.children = append(n.children, child)
+	return child
+}
+
+func (n *Node) Name() string {
+	return n.name
+}
+
+func (n *Node) Path() string {
+	if n.parent == nil {
+		return n.name
+	}
+	return n.parent.Path() + "/" + n.Name()
+}
+
+func (n *Node) FileType() string {
+	return n.fileType
+}
+
+func (n *Node) IsLast() bool {
+	return n.isLast
+}
+
+func (n *Node) Parent() (FileInfo, bool) {
+	if n.parent == nil {
+		return nil, false
+	}
+	return n.parent, true
+}
+
+func (n *Node) IsDir() bool {
+	return n.isDir
+}
+
+func (n *Node) IsSym() bool {
+	return false
+}
+
+func (n *Node) SymLink() (string, error) {
+	return "", xerrors.New("This is not symlink")
+}
+
+func (n *Node) SetError(err error) {
+	n.err = err
+}
+
+func (n *Node) Error() error {
+	return n.err
+}
+
+func (n *Node) SetHash(hash string) {
+	n.hash = hash
+}
+
+func (n *Node) Hash() string {
+	return n.hash
+}
+
+func (n *Node) SetLines(lines int) {
+	n.lines = lines
+	n.counted = true
+}
+
+func (n *Node) Lines() (int, bool) {
+	return n.lines, n.counted
+}
+
+func (n *Node) SetKind(kind FileKind) {
+	n.kind = kind
+}
+
+func (n *Node) Kind() FileKind {
+	return n.kind
+}
+
+// styleOf returns the color of the name of f, when the source of f specifies it.
+func styleOf(f FileInfo) (color.Style, bool) {
+	n, ok := f.(*Node)
+	if !ok || n.style == nil {
+		return nil, false
+	}
+	return n.style, true
+}
+
+// labelOf returns name with the value of f such as 'key: value', when f is a scalar of a document.
+// The value is only displayed, and isn't a part of the name and the path.
+func labelOf(f FileInfo, name string) string {
+	n, ok := f.(*Node)
+	if !ok || n.value == "" {
+		return name
+	}
+	return name + ": " + n.value
+}
+
+// nodeWalk sends root and its descendants in the same order as Dirwalk.
+// The search options are applied to the nodes in the same way as Dirwalk.
+func nodeWalk(root *Node, ch chan<- FileInfo, opts *ListSearchOptions) {
+	sendNode(root, ch, 0, opts)
+	close(ch)
+}
+
+func sendNode(n *Node, ch chan<- FileInfo, depth int, opts *ListSearchOptions) {
+	if !n.isDir || (opts.Level != nil && depth >= *opts.Level) {
+		ch <- n
+		return
+	}
+
+	children := filterNodes(n.children, depth+1, opts)
+	if exceedsFileLimit(len(children), opts) {
+		n.SetError(errExceedsFileLimit(len(children)))
+		ch <- n
+		return
+	}
+	ch <- n
+
+	omittedCount := 0
+	if opts.Head > 0 && len(children) > opts.Head {
+		children, omittedCount = children[:opts.Head], len(children)-opts.Head
+	}
+
+	for i, c := range children {
+		c.isLast = i == len(children)-1 && omittedCount == 0
+		sendNode(c, ch, depth+1, opts)
+	}
+
+	if omittedCount > 0 {
+		ch <- newOmitted(n, omittedCount)
+	}
+}
+
+// filterNodes returns the nodes which satisfy options.
+// depth is the depth of nodes.
+func filterNodes(nodes []*Node, depth int, opts *ListSearchOptions) []*Node {
+	result := make([]*Node, 0, len(nodes))
+	for _, n := range nodes {
+		if inString(n.name, opts.IgnorePatterns) {
+			continue
+		}
+
+		if opts.IsOnlyDirectry() && !n.isDir {
+			continue
+		}
+
+		if !opts.IsAll() && strings.HasPrefix(n.name, ".") {
+			continue
+		}
+
+		if !n.isDir && len(opts.Patterns) != 0 && !matchPatterns(n.name, opts.Patterns) {
+			continue
+		}
+
+		if !n.isDir && n.info != nil && opts.hasPredicate() && !opts.matchFile(n.info) {
+			continue
+		}
+
+		_, annotated := opts.annotations[relativePath(n)]
+		if !n.isDir && opts.IsAnnotatedOnly() && !annotated {
+			continue
+		}
+
+		if n.isDir && (opts.IsPrune() || opts.IsAnnotatedOnly()) && !hasListedNode(n, depth, opts) && !matchDirNode(n, opts) && !(opts.IsAnnotatedOnly() && annotated) {
+			continue
+		}
+
+		result = append(result, n)
+	}
+	return result
+}
+
+// hasListedNode returns true, when dir has any listed descendants after pruning.
+// Like scanDir, the descendants beyond '-L' option are also checked.
+func hasListedNode(dir *Node, depth int, opts *ListSearchOptions) bool {
+	return len(filterNodes(dir.children, depth+1, opts)) != 0
+}
+
+// matchDirNode returns true, when the directory node itself satisfies the predicates like matchDir.
+func matchDirNode(dir *Node, opts *ListSearchOptions) bool {
+	if dir.info == nil || !opts.hasDirPredicate() {
+		return false
+	}
+
+	if opts.types != "" && !matchType(dir.info, opts.types) {
+		return false
+	}
+
+	if opts.IsEmpty() && len(dir.children) != 0 {
+		return false
+	}
+	return opts.matchTime(dir.info)
+}
@@ -0,0 +1,105 @@
+package gtree
+
+import (
+	"bytes"
+	"testing"
+)
+
+func newTestNodes() *Node {
+	// root
+	// ├── a
+	// │   ├── b.txt
+	// │   └── c
+	// │       └── d.go
+	// ├── e
+	// ├── f.go
+	// └── .git
+	root := NewNode("root", "", true)
+	a := root.Add(NewNode("a", "", true))
+	a.Add(NewNode("b.txt", "txt", false))
+	a.Add(NewNode("c", "", true)).Add(NewNode("d.go", "go", false))
+	root.Add(NewNode("e", "", true))
+	root.Add(NewNode("f.go", "go", false))
+	root.Add(NewNode(".git", "", true))
+	return root
+}
+
+func TestNodeWalk(t *testing.T) {
+	level, top := 2, 1
+
+	tests := map[string]struct {
+		opts   *ListSearchOptions
+		expect string
+	}{
+		"all": {
+			opts:   &ListSearchOptions{},
+			expect: "root\n├── a\n│   ├── b.txt\n│   └── c\n│       └── d.go\n├── e\n└── f.go\n",
+		},
+		"level": {
+			opts:   &ListSearchOptions{Level: &level},
+			expect: "root\n├── a\n│   ├── b.txt\n│   └── c\n├── e\n└── f.go\n",
+		},
+		"pattern and prune": {
+			opts:   &ListSearchOptions{Patterns: []string{"*.go"}, Prune: []bool{true}},
+			expect: "root\n├── a\n│   └── c\n│       └── d.go\n└── f.go\n",
+		},
+		"prune at level": {
+			opts:   &ListSearchOptions{Patterns: []string{"*.go"}, Prune: []bool{true}, Level: &top},
+			expect: "root\n├── a\n└── f.go\n",
+		},
+		"all with dotfiles": {
+			opts:   &ListSearchOptions{All: []bool{true}, Level: &level},
+			expect: "root\n├── a\n│   ├── b.txt\n│   └── c\n├── e\n├── f.go\n└── .git\n",
+		},
+		"head": {
+			opts:   &ListSearchOptions{Head: 1},
+			expect: "root\n├── a\n│   ├── b.txt\n│   └── … 1 more entry\n└── … 2 more entries\n",
+		},
+		"filelimit": {
+			opts:   &ListSearchOptions{FileLimit: 2},
+			expect: "root [3 entries exceeds filelimit, not opening dir]\n",
+		},
+		"annotated only": {
+			opts: &ListSearchOptions{
+				AnnotatedOnly: []bool{true},
+				annotations:   annotations{"a/c/d.go": "main", "e": "empty"},
+			},
+			expect: "root\n├── a\n│   └── c\n│       └── d.go\n└── e\n",
+		},
+		"only directory": {
+			opts:   &ListSearchOptions{OnlyDirectory: []bool{true}, IgnorePatterns: []string{"c"}},
+			expect: "root\n├── a\n└── e\n",
+		},
+	}
+
+	for key, tt := range tests {
+		t.Run(key, func(t *testing.T) {
+			p := NewPrinter(&ListDisplayOptions{NoIcons: []bool{true}})
+
+			buffer := new(bytes.Buffer)
+			ch := make(chan FileInfo)
+			go nodeWalk(newTestNodes(), ch, tt.opts)
+			for f := range ch {
+				if err := p.Write(buffer, f); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got := ansiEscape.ReplaceAllString(buffer.String(), ""); got != tt.expect {
+				t.Errorf("nodeWalk() expected '%s', got '%s'", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestNode_Path(t *testing.T) {
+	root := newTestNodes()
+	d := root.children[0].children[1].children[0]
+
+	if got := d.Path(); got != "root/a/c/d.go" {
+		t.Errorf("node.Path() expected 'root/a/c/d.go', got '%s'", got)
+	}
+	if got := relativePath(d); got != "a/c/d.go" {
+		t.Errorf("relativePath() expected 'a/c/d.go', got '%s'", got)
+	}
+}
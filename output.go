@@ -0,0 +1,136 @@
+package gtree
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+var errFileExist = fmt.Errorf("output file already exists")
+
+// checkOverWrite returns error, when the output file exists and it must not be overwritten.
+// Without '--force' and '--no-clobber' options, user is asked only if stdin is interactive.
+// Devices and named pipes such as /dev/stdout aren't overwritten, since they have no content to lose.
+func checkOverWrite(filename string, opt *ListDisplayOptions, interactive bool) error {
+	info, err := os.Stat(filename)
+	if os.IsNotExist(err) || (err == nil && !info.Mode().IsRegular()) {
+		return nil
+	}
+
+	switch {
+	case opt.IsForce():
+		return nil
+	case opt.IsNoClobber():
+		return errFileExist
+	case !interactive:
+		return fmt.Errorf("%w, use --force to overwrite", errFileExist)
+	}
+
+	fmt.Fprintf(os.Stderr, "Output file already exists. Are you sure to overwrite %s?[Y/n] ", filename)
+
+	var answer string
+	if _, err := fmt.Scan(&answer); err != nil {
+		return xerrors.Errorf("failed to scan answer: %v", err)
+	}
+
+	if strings.ToLower(strings.TrimRight(answer, "\n")) != "y" {
+		return errFileExist
+	}
+	return nil
+}
+
+// openOutput returns the writer for the output file or stdout, and the function to finish writing.
+// The new or regular output file is written to a temporary file in the same directory,
+// and it is renamed to the output file only when the writing succeeds,
+// so that a failure never leaves a truncated file behind.
+// Symlinks are followed, and the other files such as devices and named pipes are written directly.
+func openOutput(opt *ListDisplayOptions, interactive bool) (io.Writer, func(ok bool) error, error) {
+	if opt.Output == "" {
+		return os.Stdout, func(bool) error { return nil }, nil
+	}
+
+	if err := checkOverWrite(opt.Output, opt, interactive); err != nil {
+		return nil, nil, xerrors.Errorf("denided overwrite: %w", err)
+	}
+
+	output := opt.Output
+	if resolved, err := filepath.EvalSymlinks(output); err == nil {
+		output = resolved
+	}
+
+	// The dangling symlink is also written directly, so that its target is created.
+	if info, err := os.Lstat(output); err == nil && !info.Mode().IsRegular() {
+		return openDirectOutput(output)
+	}
+
+	dir, base := filepath.Split(output)
+	if dir == "" {
+		dir = "."
+	}
+
+	tmp, err := ioutil.TempFile(dir, "."+base+".*.tmp")
+	if err != nil {
+		return nil, nil, xerrors.Errorf("file create/open error: %w", err)
+	}
+
+	mode := os.FileMode(0644)
+	if f, err := os.Stat(output); err == nil {
+		mode = f.Mode().Perm()
+	}
+
+	finish := func(ok bool) error {
+		err := tmp.Close()
+		if !ok {
+			os.Remove(tmp.Name())
+			return nil
+		}
+
+		if err != nil {
+			os.Remove(tmp.Name())
+			return xerrors.Errorf("failed to close output: %w", err)
+		}
+
+		if err := os.Chmod(tmp.Name(), mode); err != nil {
+			os.Remove(tmp.Name())
+			return xerrors.Errorf("failed to change mode of output: %w", err)
+		}
+
+		if err := os.Rename(tmp.Name(), output); err != nil {
+			os.Remove(tmp.Name())
+			return xerrors.Errorf("failed to rename output: %w", err)
+		}
+		return nil
+	}
+	return tmp, finish, nil
+}
+
+// openDirectOutput opens the output file which can't be replaced by renaming, and writes to it directly.
+func openDirectOutput(output string) (io.Writer, func(ok bool) error, error) {
+	f, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("file create/open error: %w", err)
+	}
+
+	finish := func(ok bool) error {
+		if err := f.Close(); err != nil && ok {
+			return xerrors.Errorf("failed to close output: %w", err)
+		}
+		return nil
+	}
+	return f, finish, nil
+}
+
+// flushOutput writes the buffered output.
+// The error such as no space left is returned, so that the truncated output file isn't committed.
+func flushOutput(w *bufio.Writer) error {
+	if err := w.Flush(); err != nil {
+		return xerrors.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+//go:build linux
+// +build linux
+
+package gtree
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestOpenOutput_FIFO(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gtree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "fifo")
+	if err := syscall.Mkfifo(path, 0644); err != nil {
+		t.Skipf("mkfifo isn't supported: %v", err)
+	}
+
+	read := make(chan string)
+	go func() {
+		b, _ := ioutil.ReadFile(path)
+		read <- string(b)
+	}()
+
+	// The existing fifo isn't denied, since writing to it overwrites nothing.
+	w, finish, err := openOutput(&ListDisplayOptions{Output: path}, false)
+	if err != nil {
+		t.Fatalf("openOutput() returns error: %v", err)
+	}
+	io.WriteString(w, "tree")
+	if err := finish(true); err != nil {
+		t.Fatalf("finish() returns error: %v", err)
+	}
+
+	if got := <-read; got != "tree" {
+		t.Errorf("fifo expected tree, got %s", got)
+	}
+	if f, err := os.Lstat(path); err != nil || f.Mode()&os.ModeNamedPipe == 0 {
+		t.Errorf("the fifo is replaced: %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package gtree
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/xerrors"
+)
+
+func TestCheckOverWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gtree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	exist := filepath.Join(dir, "exist.txt")
+	if err := ioutil.WriteFile(exist, []byte("exist"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		filename string
+		opt      *ListDisplayOptions
+		isErr    bool
+	}{
+		"new file":                   {filepath.Join(dir, "new.txt"), &ListDisplayOptions{}, false},
+		"new file with no clobber":   {filepath.Join(dir, "new.txt"), &ListDisplayOptions{NoClobber: []bool{true}}, false},
+		"force":                      {exist, &ListDisplayOptions{Force: []bool{true}}, false},
+		"no clobber":                 {exist, &ListDisplayOptions{NoClobber: []bool{true}}, true},
+		"non-interactive by default": {exist, &ListDisplayOptions{}, true},
+	}
+
+	for key, tt := range tests {
+		t.Run(key, func(t *testing.T) {
+			err := checkOverWrite(tt.filename, tt.opt, false)
+			if (err != nil) != tt.isErr {
+				t.Fatalf("checkOverWrite() expected error %v, got %v", tt.isErr, err)
+			}
+			if err != nil && !xerrors.Is(err, errFileExist) {
+				t.Errorf("checkOverWrite() expected errFileExist, got %v", err)
+			}
+		})
+	}
+}
+
+func TestOpenOutput(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gtree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	output := filepath.Join(dir, "tree.txt")
+	if err := ioutil.WriteFile(output, []byte("old"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	opt := &ListDisplayOptions{Output: output, Force: []bool{true}}
+
+	write := func(content string, ok bool) {
+		w, finish, err := openOutput(opt, false)
+		if err != nil {
+			t.Fatalf("openOutput() returns error: %v", err)
+		}
+		io.WriteString(w, content)
+		if err := finish(ok); err != nil {
+			t.Fatalf("finish() returns error: %v", err)
+		}
+	}
+
+	assert := func(expect string) {
+		b, err := ioutil.ReadFile(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != expect {
+			t.Errorf("output expected %s, got %s", expect, b)
+		}
+
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != 1 {
+			t.Errorf("temporary files are left: %v", files)
+		}
+	}
+
+	// Failed writing keeps the old file.
+	write("failed", false)
+	assert("old")
+
+	write("new", true)
+	assert("new")
+
+	f, err := os.Stat(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Mode().Perm() != 0600 {
+		t.Errorf("output mode expected 0600, got %v", f.Mode().Perm())
+	}
+}
+
+func TestOpenOutput_Symlink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gtree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	target := filepath.Join(dir, "target.txt")
+	if err := ioutil.WriteFile(target, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link.txt")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlink isn't supported: %v", err)
+	}
+
+	w, finish, err := openOutput(&ListDisplayOptions{Output: link, Force: []bool{true}}, false)
+	if err != nil {
+		t.Fatalf("openOutput() returns error: %v", err)
+	}
+	io.WriteString(w, "new")
+	if err := finish(true); err != nil {
+		t.Fatalf("finish() returns error: %v", err)
+	}
+
+	// The target is written, and the symlink is kept.
+	if f, err := os.Lstat(link); err != nil || f.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("the symlink is replaced: %v", err)
+	}
+	if b, err := ioutil.ReadFile(target); err != nil || string(b) != "new" {
+		t.Errorf("target expected new, got %s (%v)", b, err)
+	}
+}
@@ -0,0 +1,43 @@
+//go:build !windows
+// +build !windows
+
+package gtree
+
+import (
+	"os"
+	"os/user"
+	"strconv"
+	"sync"
+	"syscall"
+)
+
+var (
+	ownersMu sync.Mutex
+
+	// owners is the user names looked up by the user ids.
+	owners = make(map[uint32]string)
+)
+
+// fileOwner returns the user name of the owner of the file on the disk.
+// The user id is returned when it has no name.
+func fileOwner(info os.FileInfo) (string, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "", false
+	}
+
+	ownersMu.Lock()
+	defer ownersMu.Unlock()
+
+	if name, ok := owners[stat.Uid]; ok {
+		return name, true
+	}
+
+	uid := strconv.FormatUint(uint64(stat.Uid), 10)
+	name := uid
+	if u, err := user.LookupId(uid); err == nil {
+		name = u.Username
+	}
+	owners[stat.Uid] = name
+	return name, true
+}
@@ -0,0 +1,8 @@
+package gtree
+
+import "os"
+
+// fileOwner returns false, since the owner of the file isn't available without the security API.
+func fileOwner(info os.FileInfo) (string, bool) {
+	return "", false
+}
@@ -0,0 +1,214 @@
+package gtree
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+var sizeUnits = map[string]int64{
+	"":  1,
+	"k": 1 << 10,
+	"m": 1 << 20,
+	"g": 1 << 30,
+	"t": 1 << 40,
+}
+
+// parseSize parses size such as '512', '10k', '10M', '1.5GB' or '2GiB'.
+func parseSize(s string) (int64, error) {
+	str := strings.ToLower(strings.TrimSpace(s))
+	str = strings.TrimSuffix(strings.TrimSuffix(str, "b"), "i")
+
+	i := len(str)
+	for i > 0 && (str[i-1] < '0' || str[i-1] > '9') && str[i-1] != '.' {
+		i--
+	}
+
+	unit, ok := sizeUnits[str[i:]]
+	if !ok || i == 0 {
+		return 0, xerrors.Errorf("invalid size: %s", s)
+	}
+
+	n, err := strconv.ParseFloat(str[:i], 64)
+	if err != nil || n < 0 {
+		return 0, xerrors.Errorf("invalid size: %s", s)
+	}
+	return int64(n * float64(unit)), nil
+}
+
+var ageUnits = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+// parseTime parses the age such as '2d' or '3h' relative to now,
+// or the date such as '2006-01-02' and RFC3339.
+func parseTime(s string, now time.Time) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	if len(s) < 2 {
+		return time.Time{}, xerrors.Errorf("invalid time: %s", s)
+	}
+
+	unit, ok := ageUnits[s[len(s)-1:]]
+	if !ok {
+		return time.Time{}, xerrors.Errorf("invalid time: %s", s)
+	}
+
+	n, err := strconv.ParseFloat(s[:len(s)-1], 64)
+	if err != nil || n < 0 {
+		return time.Time{}, xerrors.Errorf("invalid time: %s", s)
+	}
+	return now.Add(-time.Duration(n * float64(unit))), nil
+}
+
+// parsePredicates validates the predicate options, and stores the parsed values.
+func (l *ListSearchOptions) parsePredicates(now time.Time) error {
+	if l.MinSize != "" {
+		size, err := parseSize(l.MinSize)
+		if err != nil {
+			return err
+		}
+		l.minSize = &size
+	}
+
+	if l.MaxSize != "" {
+		size, err := parseSize(l.MaxSize)
+		if err != nil {
+			return err
+		}
+		l.maxSize = &size
+	}
+
+	if l.Newer != "" {
+		t, err := parseTime(l.Newer, now)
+		if err != nil {
+			return err
+		}
+		l.newer = t
+	}
+
+	if l.Older != "" {
+		t, err := parseTime(l.Older, now)
+		if err != nil {
+			return err
+		}
+		l.older = t
+	}
+
+	for _, p := range l.Patterns {
+		if _, err := filepath.Match(p, ""); err != nil {
+			return xerrors.Errorf("invalid pattern: %s", p)
+		}
+	}
+
+	l.types = ""
+	for _, t := range strings.Split(l.Type, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		if len(t) != 1 || !strings.Contains("fdlxps", t) {
+			return xerrors.Errorf("invalid type: %s, must be f, d, l, x, p or s", t)
+		}
+		l.types += t
+	}
+	return nil
+}
+
+// hasPredicate returns true, when any predicate is specified.
+func (l *ListSearchOptions) hasPredicate() bool {
+	return l.minSize != nil || l.maxSize != nil || !l.newer.IsZero() || !l.older.IsZero() || l.types != "" || l.IsEmpty()
+}
+
+// hasDirPredicate returns true, when predicates can be satisfied by directories.
+func (l *ListSearchOptions) hasDirPredicate() bool {
+	return strings.Contains(l.types, "d") || l.IsEmpty()
+}
+
+func matchType(f os.FileInfo, types string) bool {
+	mode := f.Mode()
+	for _, t := range types {
+		switch {
+		case t == 'f' && mode.IsRegular(),
+			t == 'd' && mode.IsDir(),
+			t == 'l' && mode&os.ModeSymlink != 0,
+			t == 'x' && mode.IsRegular() && mode&0111 != 0,
+			t == 'p' && mode&os.ModeNamedPipe != 0,
+			t == 's' && mode&os.ModeSocket != 0:
+			return true
+		}
+	}
+	return false
+}
+
+func (l *ListSearchOptions) matchTime(f os.FileInfo) bool {
+	if !l.newer.IsZero() && !f.ModTime().After(l.newer) {
+		return false
+	}
+
+	if !l.older.IsZero() && !f.ModTime().Before(l.older) {
+		return false
+	}
+	return true
+}
+
+// matchFile returns true, when the file which isn't directory satisfies the predicates.
+func (l *ListSearchOptions) matchFile(f os.FileInfo) bool {
+	if l.minSize != nil && f.Size() < *l.minSize {
+		return false
+	}
+
+	if l.maxSize != nil && f.Size() > *l.maxSize {
+		return false
+	}
+
+	if l.IsEmpty() && (!f.Mode().IsRegular() || f.Size() != 0) {
+		return false
+	}
+
+	if l.types != "" && !matchType(f, l.types) {
+		return false
+	}
+	return l.matchTime(f)
+}
+
+// matchDir returns true, when the directory itself satisfies the predicates.
+// Directories are listed regardless of the result to show the tree structure.
+func (l *ListSearchOptions) matchDir(path string, f os.FileInfo) bool {
+	if !l.hasDirPredicate() {
+		return false
+	}
+
+	if l.types != "" && !matchType(f, l.types) {
+		return false
+	}
+
+	if l.IsEmpty() && !isEmptyDir(path) {
+		return false
+	}
+	return l.matchTime(f)
+}
+
+func isEmptyDir(path string) bool {
+	d, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer d.Close()
+
+	_, err = d.Readdirnames(1)
+	return err == io.EOF
+}
@@ -0,0 +1,133 @@
+package gtree
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{
+		"512":   512,
+		"10k":   10 << 10,
+		"10K":   10 << 10,
+		"10M":   10 << 20,
+		"10MB":  10 << 20,
+		"2GiB":  2 << 30,
+		"1.5kb": 1536,
+		"0":     0,
+	}
+
+	for in, expect := range tests {
+		got, err := parseSize(in)
+		if err != nil {
+			t.Errorf("parseSize(%s) returns error: %v", in, err)
+			continue
+		}
+		if got != expect {
+			t.Errorf("parseSize(%s) expected %d, got %d", in, expect, got)
+		}
+	}
+
+	for _, in := range []string{"", "M", "10X", "-1", "abc"} {
+		if _, err := parseSize(in); err == nil {
+			t.Errorf("parseSize(%s) expected error", in)
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2020, 1, 10, 12, 0, 0, 0, time.Local)
+	tests := map[string]time.Time{
+		"2d":         now.Add(-48 * time.Hour),
+		"3h":         now.Add(-3 * time.Hour),
+		"1w":         now.Add(-7 * 24 * time.Hour),
+		"2020-01-02": time.Date(2020, 1, 2, 0, 0, 0, 0, time.Local),
+	}
+
+	for in, expect := range tests {
+		got, err := parseTime(in, now)
+		if err != nil {
+			t.Errorf("parseTime(%s) returns error: %v", in, err)
+			continue
+		}
+		if !got.Equal(expect) {
+			t.Errorf("parseTime(%s) expected %v, got %v", in, expect, got)
+		}
+	}
+
+	for _, in := range []string{"", "d", "2y", "yesterday"} {
+		if _, err := parseTime(in, now); err == nil {
+			t.Errorf("parseTime(%s) expected error", in)
+		}
+	}
+}
+
+type dummyPredicateFileInfo struct {
+	dummySearchFileInfo
+	size    int64
+	mode    os.FileMode
+	modTime time.Time
+}
+
+func (d *dummyPredicateFileInfo) Size() int64 {
+	return d.size
+}
+
+func (d *dummyPredicateFileInfo) Mode() os.FileMode {
+	return d.mode
+}
+
+func (d *dummyPredicateFileInfo) ModTime() time.Time {
+	return d.modTime
+}
+
+func TestListSearchOptions_matchFile(t *testing.T) {
+	now := time.Date(2020, 1, 10, 12, 0, 0, 0, time.Local)
+	small := &dummyPredicateFileInfo{size: 100, modTime: now.Add(-time.Hour)}
+	large := &dummyPredicateFileInfo{size: 20 << 20, modTime: now.Add(-72 * time.Hour)}
+	empty := &dummyPredicateFileInfo{size: 0, modTime: now}
+	executable := &dummyPredicateFileInfo{size: 100, mode: 0755, modTime: now}
+	symlink := &dummyPredicateFileInfo{mode: os.ModeSymlink, modTime: now}
+
+	tests := map[string]struct {
+		opts   *ListSearchOptions
+		file   os.FileInfo
+		expect bool
+	}{
+		"min size matches":     {&ListSearchOptions{MinSize: "10M"}, large, true},
+		"min size unmatched":   {&ListSearchOptions{MinSize: "10M"}, small, false},
+		"max size matches":     {&ListSearchOptions{MaxSize: "1k"}, small, true},
+		"max size unmatched":   {&ListSearchOptions{MaxSize: "1k"}, large, false},
+		"newer matches":        {&ListSearchOptions{Newer: "2d"}, small, true},
+		"newer unmatched":      {&ListSearchOptions{Newer: "2d"}, large, false},
+		"older matches":        {&ListSearchOptions{Older: "2d"}, large, true},
+		"older unmatched":      {&ListSearchOptions{Older: "2d"}, small, false},
+		"empty matches":        {&ListSearchOptions{Empty: []bool{true}}, empty, true},
+		"empty unmatched":      {&ListSearchOptions{Empty: []bool{true}}, small, false},
+		"type x matches":       {&ListSearchOptions{Type: "x"}, executable, true},
+		"type x unmatched":     {&ListSearchOptions{Type: "x"}, small, false},
+		"type l matches":       {&ListSearchOptions{Type: "f,l"}, symlink, true},
+		"type d unmatched":     {&ListSearchOptions{Type: "d"}, small, false},
+		"combination matches":  {&ListSearchOptions{Type: "f", MaxSize: "1k", Newer: "1d"}, small, true},
+		"combination unmatchd": {&ListSearchOptions{Type: "f", MaxSize: "1k", Newer: "1d"}, large, false},
+	}
+
+	for key, tt := range tests {
+		t.Run(key, func(t *testing.T) {
+			if err := tt.opts.parsePredicates(now); err != nil {
+				t.Fatalf("parsePredicates() returns error: %v", err)
+			}
+			if got := tt.opts.matchFile(tt.file); got != tt.expect {
+				t.Errorf("matchFile() expected %v, got %v", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestListSearchOptions_parsePredicatesInvalidType(t *testing.T) {
+	opts := &ListSearchOptions{Type: "f,z"}
+	if err := opts.parsePredicates(time.Now()); err == nil {
+		t.Errorf("parsePredicates() expected error for invalid type")
+	}
+}
@@ -1,53 +1,207 @@
-package main
+package gtree
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/gookit/color"
 	"golang.org/x/xerrors"
 )
 
 var (
-	folderColor = color.New(defaultFolderIcon.Color)
-	symColor    = color.New(color.FgLightCyan)
+	folderColor  = color.New(defaultFolderIcon.Color)
+	symColor     = color.New(color.FgLightCyan)
+	dupColor     = color.New(color.FgLightYellow)
+	changedColor = color.New(color.FgLightGreen, color.OpBold)
 )
 
 // Printer write FileInfo as tree.
 type Printer struct {
 	opt *ListDisplayOptions
+
+	lines treeLines
+
+	// childPrefixes is the prefix of children's prefix for each directory.
+	// The directory is deleted when it's left, so that only the ancestors of the current file are kept.
+	childPrefixes map[FileInfo]string
+
+	// duplicates is the number of files for each duplicated digest.
+	duplicates map[string]int
+
+	// highlights is the paths which are highlighted such as changed files.
+	highlights map[string]bool
+
+	// annotations is descriptions of files.
+	annotations annotations
+
+	// columns is the width of the widest entry among siblings keyed by their parent's path.
+	columns map[string]int
 }
 
 // NewPrinter return Printer pointer.
 func NewPrinter(opt *ListDisplayOptions) *Printer {
 	return &Printer{
-		opt: opt,
+		opt:           opt,
+		lines:         newTreeLines(opt.Charset, opt.Indent),
+		childPrefixes: make(map[FileInfo]string),
+	}
+}
+
+// childPrefix returns the prefix of children's prefix.
+func (p *Printer) childPrefix(dir FileInfo) string {
+	parent, ok := dir.Parent()
+	if !ok {
+		return ""
+	}
+
+	if prefix, ok := p.childPrefixes[dir]; ok {
+		return prefix
 	}
+
+	prefix := p.childPrefix(parent)
+	if dir.IsLast() {
+		prefix += p.lines.blank
+	} else {
+		prefix += p.lines.vertical
+	}
+	p.childPrefixes[dir] = prefix
+	return prefix
 }
 
 func (p *Printer) writePrefix(w io.Writer, f FileInfo, prefix string) (err error) {
 	if f.IsLast() {
-		_, err = w.Write([]byte(prefix + "└── "))
+		_, err = w.Write([]byte(prefix + p.lines.last))
 	} else {
-		_, err = w.Write([]byte(prefix + "├── "))
+		_, err = w.Write([]byte(prefix + p.lines.branch))
+	}
+
+	if err != nil {
+		return xerrors.Errorf("failed to write: %w", err)
+	}
+	return nil
+}
+
+// Begin does nothing, since the text tree has no header.
+func (p *Printer) Begin(w io.Writer) error {
+	return nil
+}
+
+// End writes the report and the statistics after all trees.
+func (p *Printer) End(w io.Writer, r *Report) error {
+	return writeSummary(w, r, p.opt)
+}
+
+// EnterDir writes the line of the directory.
+func (p *Printer) EnterDir(w io.Writer, e *Entry) error {
+	return p.Write(w, e.FileInfo)
+}
+
+// Entry writes the line of the file.
+func (p *Printer) Entry(w io.Writer, e *Entry) error {
+	return p.Write(w, e.FileInfo)
+}
+
+// LeaveDir forgets the prefix of the directory, since the lines of descendants are already written.
+func (p *Printer) LeaveDir(w io.Writer, e *Entry) error {
+	delete(p.childPrefixes, e.FileInfo)
+	return nil
+}
+
+// SetDuplicates sets the digests which appear more than once in the tree.
+// Files which have these digests are highlighted.
+func (p *Printer) SetDuplicates(duplicates map[string]int) {
+	p.duplicates = duplicates
+}
+
+// SetHighlights sets the paths of files which are highlighted.
+func (p *Printer) SetHighlights(highlights map[string]bool) {
+	p.highlights = highlights
+}
+
+func (p *Printer) isDuplicate(f FileInfo) bool {
+	if f.Hash() == "" {
+		return false
+	}
+	_, ok := p.duplicates[f.Hash()]
+	return ok
+}
+
+// writeMeta writes the metadata column such as '[d41d8cd98f00] ' and '[   120] '.
+func (p *Printer) writeMeta(w io.Writer, f FileInfo) error {
+	var meta string
+	if hash := f.Hash(); p.opt.Hash != "" && !f.IsDir() && hash != "" {
+		meta += "[" + p.opt.digest(hash) + "] "
+	}
+
+	if p.opt.IsLines() && !IsOmitted(f) {
+		if lines, ok := f.Lines(); ok {
+			meta += fmt.Sprintf("[%*d] ", linesWidth, lines)
+		} else {
+			meta += fmt.Sprintf("[%*s] ", linesWidth, "-")
+		}
+	}
+
+	if meta == "" {
+		return nil
+	}
+
+	_, err := w.Write([]byte(meta))
+	if err != nil {
+		return xerrors.Errorf("failed to write: %w", err)
+	}
+	return nil
+}
+
+// iconString returns the icon of f.
+// When '--classify' option is specified, the kind of f is preferred to the file type.
+func (p *Printer) iconString(f FileInfo) string {
+	icon := iconOf(f, p.opt.IsClassify())
+	return color.New(icon.Color).Sprint(icon.Icon)
+}
+
+func (p *Printer) Write(w io.Writer, f FileInfo) error {
+	var b bytes.Buffer
+	err := p.writeEntry(&b, f)
+	if err != nil {
+		return err
 	}
 
+	p.writeAnnotation(&b, f, b.String())
+	b.WriteString("\n")
+
+	_, err = w.Write(b.Bytes())
 	if err != nil {
 		return xerrors.Errorf("failed to write: %w", err)
 	}
 	return nil
 }
 
-func (p *Printer) Write(w io.Writer, f FileInfo) (err error) {
+// writeEntry writes the line of f without the annotation and the line break.
+func (p *Printer) writeEntry(w io.Writer, f FileInfo) (err error) {
 	if pa, ok := f.Parent(); ok {
-		err = p.writePrefix(w, f, pa.ChildPrefix())
+		err = p.writePrefix(w, f, p.childPrefix(pa))
 		if err != nil {
 			return xerrors.Errorf("failed to writePrefix: %w", err)
 		}
 	}
 
+	err = p.writeMeta(w, f)
+	if err != nil {
+		return xerrors.Errorf("failed to writeMeta: %w", err)
+	}
+
+	if IsOmitted(f) {
+		_, err = w.Write([]byte(f.Name()))
+		if err != nil {
+			return xerrors.Errorf("failed to write: %w", err)
+		}
+		return nil
+	}
+
 	if !f.IsDir() && !p.opt.NoIcon() {
-		_, err = w.Write([]byte(NewIconString(f.FileType()) + " "))
+		_, err = w.Write([]byte(p.iconString(f) + " "))
 		if err != nil {
 			return xerrors.Errorf("failed to write: %w", err)
 		}
@@ -55,15 +209,28 @@ func (p *Printer) Write(w io.Writer, f FileInfo) (err error) {
 
 	var writtenName string
 	if p.opt.IsFullPath() {
-		writtenName = f.Path()
+		writtenName = p.opt.safeName(labelOf(f, f.Path()))
 	} else {
-		writtenName = f.Name()
+		writtenName = p.opt.safeName(labelOf(f, f.Name()))
 	}
 
+	style, styled := styleOf(f)
 	switch {
+	case p.highlights[f.Path()] && !f.IsSym():
+		if f.IsDir() && !p.opt.NoIcon() {
+			_, err = w.Write([]byte(changedColor.Sprintf("%s %s", defaultFolderIcon.Icon, writtenName)))
+		} else {
+			_, err = w.Write([]byte(changedColor.Sprint(writtenName)))
+		}
+	case styled:
+		if f.IsDir() && !p.opt.NoIcon() {
+			_, err = w.Write([]byte(style.Sprintf("%s %s", defaultFolderIcon.Icon, writtenName)))
+		} else {
+			_, err = w.Write([]byte(style.Sprint(writtenName)))
+		}
 	case f.IsDir():
 		if p.opt.NoIcon() {
-			_, err = w.Write([]byte(folderColor.Sprintf(writtenName)))
+			_, err = w.Write([]byte(folderColor.Sprint(writtenName)))
 		} else {
 			_, err = w.Write([]byte(folderColor.Sprintf("%s %s", defaultFolderIcon.Icon, writtenName)))
 		}
@@ -75,7 +242,13 @@ func (p *Printer) Write(w io.Writer, f FileInfo) (err error) {
 			return xerrors.Errorf("failed to retrieve symlink path: %w", err)
 		}
 
-		_, err = w.Write([]byte(fmt.Sprintf("%s -> %s", symColor.Sprint(writtenName), symLink)))
+		_, err = w.Write([]byte(fmt.Sprintf("%s -> %s", symColor.Sprint(writtenName), p.opt.safeName(symLink))))
+	case p.isDuplicate(f):
+		_, err = w.Write([]byte(fmt.Sprintf("%s (%s)", dupColor.Sprint(writtenName), plural(p.duplicates[f.Hash()]-1, "duplicate", "duplicates"))))
+	case p.opt.IsClassify() && f.Kind() == kindExecutable:
+		_, err = w.Write([]byte(executableColor.Sprint(writtenName)))
+	case p.opt.IsClassify() && f.Kind() == kindGenerated:
+		_, err = w.Write([]byte(generatedColor.Sprint(writtenName)))
 	default:
 		_, err = w.Write([]byte(writtenName))
 	}
@@ -85,15 +258,57 @@ func (p *Printer) Write(w io.Writer, f FileInfo) (err error) {
 	}
 
 	if err := f.Error(); err != nil {
-		_, err = w.Write([]byte(fmt.Sprintf(" [%s]", err.Error())))
+		_, err = w.Write([]byte(fmt.Sprintf(" [%s]", p.opt.escape(err.Error(), false))))
 		if err != nil {
 			return xerrors.Errorf("failed to write: %w", err)
 		}
 	}
+	return nil
+}
 
-	_, err = w.Write([]byte("\n"))
-	if err != nil {
-		return xerrors.Errorf("failed to write: %w", err)
+// SetAnnotations sets descriptions of files.
+// The descriptions of siblings in files are aligned.
+func (p *Printer) SetAnnotations(a annotations, files []FileInfo) {
+	p.annotations = a
+	p.columns = annotationColumns(files, func(f FileInfo) int {
+		var b bytes.Buffer
+		if err := p.writeEntry(&b, f); err != nil {
+			return 0
+		}
+		return p.opt.displayWidth(b.String())
+	})
+}
+
+// writeAnnotation writes the description of f after entry which is already written.
+func (p *Printer) writeAnnotation(b *bytes.Buffer, f FileInfo, entry string) {
+	d, ok := p.annotations.lookup(f)
+	if !ok {
+		return
 	}
-	return nil
+
+	pad := p.columns[parentKey(f)] - p.opt.displayWidth(entry)
+	if pad < 0 {
+		pad = 0
+	}
+	b.WriteString(strings.Repeat(" ", pad) + "  # " + d)
+}
+
+// parentKey returns the key of siblings.
+func parentKey(f FileInfo) string {
+	if p, ok := f.Parent(); ok {
+		return p.Path()
+	}
+	return ""
+}
+
+// annotationColumns returns the widest entry among siblings for each parent.
+func annotationColumns(files []FileInfo, width func(f FileInfo) int) map[string]int {
+	result := make(map[string]int)
+	for _, f := range files {
+		key := parentKey(f)
+		if w := width(f); w > result[key] {
+			result[key] = w
+		}
+	}
+	return result
 }
@@ -1,4 +1,4 @@
-package main
+package gtree
 
 import (
 	"bytes"
@@ -6,31 +6,34 @@ import (
 	"fmt"
 	"io"
 	"testing"
+
+	"github.com/gookit/color"
 )
 
 type dummyPrinterFileInfo struct {
-	name        string
-	path        string
-	filetype    string
-	childPrefix string
-	symlink     string
-	isLast      bool
-	isDir       bool
-	parent      FileInfo
-	err         error
-}
-
-func newDummyPrinterFileInfo(name, path, filetype, childPrefix, symlink string, isLast, isDir bool, err error, parent FileInfo) FileInfo {
+	name     string
+	path     string
+	filetype string
+	symlink  string
+	isLast   bool
+	isDir    bool
+	parent   FileInfo
+	err      error
+	hash     string
+	lines    *int
+	kind     FileKind
+}
+
+func newDummyPrinterFileInfo(name, path, filetype, symlink string, isLast, isDir bool, err error, parent FileInfo) FileInfo {
 	return &dummyPrinterFileInfo{
-		name:        name,
-		path:        path,
-		filetype:    filetype,
-		childPrefix: childPrefix,
-		symlink:     symlink,
-		isLast:      isLast,
-		isDir:       isDir,
-		parent:      parent,
-		err:         err,
+		name:     name,
+		path:     path,
+		filetype: filetype,
+		symlink:  symlink,
+		isLast:   isLast,
+		isDir:    isDir,
+		parent:   parent,
+		err:      err,
 	}
 }
 
@@ -68,10 +71,6 @@ func (d *dummyPrinterFileInfo) Parent() (FileInfo, bool) {
 	return d.parent, true
 }
 
-func (d *dummyPrinterFileInfo) ChildPrefix() string {
-	return d.childPrefix
-}
-
 func (d *dummyPrinterFileInfo) Write(w io.Writer, isFullPath bool) error {
 	return nil
 }
@@ -87,8 +86,43 @@ func (d *dummyPrinterFileInfo) Error() error {
 	return d.err
 }
 
+func (d *dummyPrinterFileInfo) SetHash(hash string) {
+	d.hash = hash
+}
+
+func (d *dummyPrinterFileInfo) Hash() string {
+	return d.hash
+}
+
+func (d *dummyPrinterFileInfo) SetLines(lines int) {
+	d.lines = &lines
+}
+
+func (d *dummyPrinterFileInfo) Lines() (int, bool) {
+	if d.lines == nil {
+		return 0, false
+	}
+	return *d.lines, true
+}
+
+func (d *dummyPrinterFileInfo) SetKind(kind FileKind) {
+	d.kind = kind
+}
+
+func (d *dummyPrinterFileInfo) Kind() FileKind {
+	return d.kind
+}
+
+func newDummyHashedFileInfo(name, hash string) FileInfo {
+	f := newDummyPrinterFileInfo(name, name, "go", "", false, false, nil, nil)
+	f.SetHash(hash)
+	return f
+}
+
 func TestPrinter_Write(t *testing.T) {
 	noDisplayOption := &ListDisplayOptions{}
+	// percentName is a variable, since go vet reports Sprint whose constant argument has a format directive.
+	percentName := "100%d"
 
 	tests := map[string]struct {
 		fileInfo      FileInfo
@@ -96,12 +130,12 @@ func TestPrinter_Write(t *testing.T) {
 		output        string
 	}{
 		"print normal": {
-			fileInfo:      newDummyPrinterFileInfo("test.go", "test/test.go", "go", "", "", false, false, nil, nil),
+			fileInfo:      newDummyPrinterFileInfo("test.go", "test/test.go", "go", "", false, false, nil, nil),
 			displayOption: noDisplayOption,
 			output:        NewIconString("go") + " test.go\n",
 		},
 		"print full path": {
-			fileInfo: newDummyPrinterFileInfo("test.go", "test/test.go", "go", "", "", false, false, nil, nil),
+			fileInfo: newDummyPrinterFileInfo("test.go", "test/test.go", "go", "", false, false, nil, nil),
 			displayOption: &ListDisplayOptions{
 				FullPath: []bool{true},
 				NoIcons:  nil,
@@ -109,7 +143,7 @@ func TestPrinter_Write(t *testing.T) {
 			output: NewIconString("go") + " test/test.go\n",
 		},
 		"print no icon file": {
-			fileInfo: newDummyPrinterFileInfo("test.go", "test/test.go", "go", "", "", false, false, nil, nil),
+			fileInfo: newDummyPrinterFileInfo("test.go", "test/test.go", "go", "", false, false, nil, nil),
 			displayOption: &ListDisplayOptions{
 				FullPath: nil,
 				NoIcons:  []bool{true},
@@ -117,49 +151,131 @@ func TestPrinter_Write(t *testing.T) {
 			output: "test.go\n",
 		},
 		"print no icon directory": {
-			fileInfo: newDummyPrinterFileInfo("test", "test/test", "", "", "", false, true, nil, nil),
+			fileInfo: newDummyPrinterFileInfo("test", "test/test", "", "", false, true, nil, nil),
 			displayOption: &ListDisplayOptions{
 				FullPath: nil,
 				NoIcons:  []bool{true},
 			},
-			output: folderColor.Sprintf("test") + "\n",
+			output: folderColor.Sprint("test") + "\n",
+		},
+		"print no icon directory with percent": {
+			fileInfo: newDummyPrinterFileInfo(percentName, "test/"+percentName, "", "", false, true, nil, nil),
+			displayOption: &ListDisplayOptions{
+				FullPath: nil,
+				NoIcons:  []bool{true},
+			},
+			output: folderColor.Sprint(percentName) + "\n",
 		},
 		"print directory": {
-			fileInfo:      newDummyPrinterFileInfo("test", "test/test", "", "", "", false, true, nil, nil),
+			fileInfo:      newDummyPrinterFileInfo("test", "test/test", "", "", false, true, nil, nil),
 			displayOption: noDisplayOption,
 			output:        folderColor.Sprintf("%s %s", defaultFolderIcon.Icon, "test") + "\n",
 		},
 		"print child file": {
-			fileInfo: newDummyPrinterFileInfo("test.go", "test/test.go", "go", "", "", false, false, nil,
-				newDummyPrinterFileInfo("test", "test", "", "", "", false, true, nil, nil),
+			fileInfo: newDummyPrinterFileInfo("test.go", "test/test.go", "go", "", false, false, nil,
+				newDummyPrinterFileInfo("test", "test", "", "", false, true, nil, nil),
 			),
 			displayOption: noDisplayOption,
 			output:        "├── " + NewIconString("go") + " test.go\n",
 		},
 		"print last child file": {
-			fileInfo: newDummyPrinterFileInfo("test.go", "test/test.go", "go", "", "", true, false, nil,
-				newDummyPrinterFileInfo("test", "test", "", "", "", false, true, nil, nil),
+			fileInfo: newDummyPrinterFileInfo("test.go", "test/test.go", "go", "", true, false, nil,
+				newDummyPrinterFileInfo("test", "test", "", "", false, true, nil, nil),
 			),
 			displayOption: noDisplayOption,
 			output:        "└── " + NewIconString("go") + " test.go\n",
 		},
 		"print grandchild file": {
-			fileInfo: newDummyPrinterFileInfo("test.go", "test/test.go", "go", "", "", true, false, nil,
-				newDummyPrinterFileInfo("test", "test", "", "│   ", "", false, true, nil, nil),
+			fileInfo: newDummyPrinterFileInfo("test.go", "root/test/test.go", "go", "", true, false, nil,
+				newDummyPrinterFileInfo("test", "root/test", "", "", false, true, nil,
+					newDummyPrinterFileInfo("root", "root", "", "", false, true, nil, nil),
+				),
 			),
 			displayOption: noDisplayOption,
 			output:        "│   └── " + NewIconString("go") + " test.go\n",
 		},
 		"print symlink file": {
-			fileInfo:      newDummyPrinterFileInfo("test.go", "test/test.go", "go", "", "/test.go", false, false, nil, nil),
+			fileInfo:      newDummyPrinterFileInfo("test.go", "test/test.go", "go", "/test.go", false, false, nil, nil),
 			displayOption: noDisplayOption,
 			output:        fmt.Sprintf("%s %s -> %s\n", NewIconString("go"), symColor.Sprint("test.go"), "/test.go"),
 		},
 		"print error file": {
-			fileInfo:      newDummyPrinterFileInfo("test.go", "test/test.go", "go", "", "", false, false, errors.New("Hello"), nil),
+			fileInfo:      newDummyPrinterFileInfo("test.go", "test/test.go", "go", "", false, false, errors.New("Hello"), nil),
 			displayOption: noDisplayOption,
 			output:        NewIconString("go") + " test.go [Hello]\n",
 		},
+		"print omitted files": {
+			fileInfo: newOmitted(newDummyPrinterFileInfo("test", "root/test", "", "", false, true, nil,
+				newDummyPrinterFileInfo("root", "root", "", "", false, true, nil, nil),
+			), 19),
+			displayOption: noDisplayOption,
+			output:        "│   └── … 19 more entries\n",
+		},
+		"print hash": {
+			fileInfo: newDummyHashedFileInfo("test.go", "0123456789abcdef"),
+			displayOption: &ListDisplayOptions{
+				NoIcons: []bool{true},
+				Hash:    "sha256",
+			},
+			output: "[0123456789ab] test.go\n",
+		},
+		"print lines": {
+			fileInfo: func() FileInfo {
+				f := newDummyPrinterFileInfo("test.go", "test/test.go", "go", "", false, false, nil, nil)
+				f.SetLines(42)
+				return f
+			}(),
+			displayOption: &ListDisplayOptions{
+				NoIcons: []bool{true},
+				Lines:   []bool{true},
+			},
+			output: "[     42] test.go\n",
+		},
+		"print binary lines": {
+			fileInfo: newDummyPrinterFileInfo("test.png", "test/test.png", "png", "", false, false, nil, nil),
+			displayOption: &ListDisplayOptions{
+				NoIcons: []bool{true},
+				Lines:   []bool{true},
+			},
+			output: "[      -] test.png\n",
+		},
+		"print classified executable": {
+			fileInfo: func() FileInfo {
+				f := newDummyPrinterFileInfo("run", "test/run", "run", "", false, false, nil, nil)
+				f.SetKind(kindExecutable)
+				return f
+			}(),
+			displayOption: &ListDisplayOptions{Classify: []bool{true}},
+			output:        color.New(executableIcon.Color).Sprint(executableIcon.Icon) + " " + executableColor.Sprint("run") + "\n",
+		},
+		"print classified binary": {
+			fileInfo: func() FileInfo {
+				f := newDummyPrinterFileInfo("data.go", "test/data.go", "go", "", false, false, nil, nil)
+				f.SetKind(kindBinary)
+				return f
+			}(),
+			displayOption: &ListDisplayOptions{Classify: []bool{true}},
+			output:        color.New(binaryIcon.Color).Sprint(binaryIcon.Icon) + " data.go\n",
+		},
+		"print escaped control characters": {
+			fileInfo:      newDummyPrinterFileInfo("a\n├── b\x1b[31m", "test/a", "", "", false, false, nil, nil),
+			displayOption: &ListDisplayOptions{NoIcons: []bool{true}},
+			output:        "a\\n├── b\\x1b[31m\n",
+		},
+		"print quoted symlink": {
+			fileInfo:      newDummyPrinterFileInfo("say \"hi\"", "test/say \"hi\"", "", "/target", false, false, nil, nil),
+			displayOption: &ListDisplayOptions{NoIcons: []bool{true}, Quote: []bool{true}},
+			output:        fmt.Sprintf("%s -> %s\n", symColor.Sprint(`"say \"hi\""`), `"/target"`),
+		},
+		"print full hash": {
+			fileInfo: newDummyHashedFileInfo("test.go", "0123456789abcdef"),
+			displayOption: &ListDisplayOptions{
+				NoIcons:  []bool{true},
+				Hash:     "sha256",
+				FullHash: []bool{true},
+			},
+			output: "[0123456789abcdef] test.go\n",
+		},
 	}
 
 	for key, tt := range tests {
@@ -174,3 +290,132 @@ func TestPrinter_Write(t *testing.T) {
 		})
 	}
 }
+
+func TestPrinter_WriteDuplicate(t *testing.T) {
+	p := NewPrinter(&ListDisplayOptions{NoIcons: []bool{true}, Hash: "md5", Dup: []bool{true}})
+	p.SetDuplicates(map[string]int{"aaaa": 2})
+
+	tests := map[string]struct {
+		fileInfo FileInfo
+		output   string
+	}{
+		"duplicate": {
+			fileInfo: newDummyHashedFileInfo("a.go", "aaaa"),
+			output:   fmt.Sprintf("[aaaa] %s (1 duplicate)\n", dupColor.Sprint("a.go")),
+		},
+		"unique": {
+			fileInfo: newDummyHashedFileInfo("b.go", "bbbb"),
+			output:   "[bbbb] b.go\n",
+		},
+	}
+
+	for key, tt := range tests {
+		t.Run(key, func(t *testing.T) {
+			buffer := new(bytes.Buffer)
+			p.Write(buffer, tt.fileInfo)
+			if buffer.String() != tt.output {
+				t.Errorf("printer.Write() expected '%s', got '%s'", tt.output, buffer.String())
+			}
+		})
+	}
+}
+
+func TestPrinter_WriteAnnotation(t *testing.T) {
+	root := newDummyPrinterFileInfo("root", "root", "", "", true, true, nil, nil)
+	cmd := newDummyPrinterFileInfo("cmd", "root/cmd", "", "", false, true, nil, root)
+	main := newDummyPrinterFileInfo("main.go", "root/cmd/main.go", "go", "", true, false, nil, cmd)
+	internal := newDummyPrinterFileInfo("internal", "root/internal", "", "", true, true, nil, root)
+	files := []FileInfo{root, cmd, main, internal}
+
+	p := NewPrinter(&ListDisplayOptions{NoIcons: []bool{true}})
+	p.SetAnnotations(annotations{"cmd": "commands", "cmd/main.go": "entry point", "internal": "private packages"}, files)
+
+	expect := "root\n" +
+		folderColor.Sprint("├── cmd") + "       # commands\n" +
+		"│   └── main.go  # entry point\n" +
+		folderColor.Sprint("└── internal") + "  # private packages\n"
+
+	buffer := new(bytes.Buffer)
+	for _, f := range files {
+		p.Write(buffer, f)
+	}
+
+	if got := ansiEscape.ReplaceAllString(buffer.String(), ""); got != ansiEscape.ReplaceAllString(expect, "") {
+		t.Errorf("printer.Write() expected '%s', got '%s'", expect, got)
+	}
+}
+
+func TestPrinter_childPrefix(t *testing.T) {
+	root := NewFileInfo(newDummyOsFile("root", true), nil, false)
+	d1 := NewFileInfo(newDummyOsFile("test", true), root, false)
+	d2 := NewFileInfo(newDummyOsFile("test", true), root, true)
+	d3 := NewFileInfo(newDummyOsFile("test", true), d1, true)
+	d4 := NewFileInfo(newDummyOsFile("test", true), d2, false)
+
+	tests := map[string]struct {
+		charset string
+		indent  int
+		expect  []string
+	}{
+		"utf8":     {"utf8", 4, []string{"", "│   ", "    ", "│       ", "    │   "}},
+		"ascii":    {"ascii", 4, []string{"", "|   ", "    ", "|       ", "    |   "}},
+		"heavy":    {"heavy", 4, []string{"", "┃   ", "    ", "┃       ", "    ┃   "}},
+		"indent 2": {"utf8", 2, []string{"", "│ ", "  ", "│   ", "  │ "}},
+	}
+
+	for key, tt := range tests {
+		t.Run(key, func(t *testing.T) {
+			p := NewPrinter(&ListDisplayOptions{Charset: tt.charset, Indent: tt.indent})
+			for i, d := range []FileInfo{root, d1, d2, d3, d4} {
+				if got := p.childPrefix(d); got != tt.expect[i] {
+					t.Errorf("%d: printer.childPrefix() expected '%s', got '%s'", i, tt.expect[i], got)
+				}
+			}
+		})
+	}
+}
+
+func TestPrinter_LeaveDir(t *testing.T) {
+	p := NewPrinter(&ListDisplayOptions{NoIcons: []bool{true}, Indent: 4})
+	tw := &treeWriter{w: new(bytes.Buffer), opt: p.opt, formatter: p, report: &Report{}}
+	if err := tw.writeTree(NodeTree(newTestNodes(), &ListSearchOptions{}), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// The prefixes of the directories are forgotten after their descendants are written.
+	if len(p.childPrefixes) != 0 {
+		t.Errorf("printer.childPrefixes expected to be empty, got %v", p.childPrefixes)
+	}
+}
+
+func TestPrinter_WriteCharset(t *testing.T) {
+	root := newDummyPrinterFileInfo("root", "root", "", "", true, true, nil, nil)
+	dir := newDummyPrinterFileInfo("dir", "root/dir", "", "", false, true, nil, root)
+	file := newDummyPrinterFileInfo("a", "root/dir/a", "", "", true, false, nil, dir)
+	last := newDummyPrinterFileInfo("b", "root/b", "", "", true, false, nil, root)
+
+	tests := map[string]struct {
+		charset string
+		indent  int
+		expect  string
+	}{
+		"ascii":   {"ascii", 4, "root\n|-- dir\n|   `-- a\n`-- b\n"},
+		"rounded": {"rounded", 4, "root\n├── dir\n│   ╰── a\n╰── b\n"},
+		"double":  {"double", 3, "root\n╠═ dir\n║  ╚═ a\n╚═ b\n"},
+	}
+
+	for key, tt := range tests {
+		t.Run(key, func(t *testing.T) {
+			p := NewPrinter(&ListDisplayOptions{NoIcons: []bool{true}, Charset: tt.charset, Indent: tt.indent})
+
+			buffer := new(bytes.Buffer)
+			for _, f := range []FileInfo{root, dir, file, last} {
+				p.Write(buffer, f)
+			}
+
+			if got := ansiEscape.ReplaceAllString(buffer.String(), ""); got != tt.expect {
+				t.Errorf("printer.Write() expected '%s', got '%s'", tt.expect, got)
+			}
+		})
+	}
+}
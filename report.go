@@ -0,0 +1,82 @@
+package gtree
+
+import (
+	"fmt"
+	"io"
+
+	"golang.org/x/xerrors"
+)
+
+// Report is the summary of listed files, which is passed to Formatter at the end.
+type Report struct {
+	dirs  int
+	files int
+
+	// stats is the statistics for '--stats' option.
+	stats *stats
+}
+
+// newReport returns Report pointer which also collects the statistics by '--stats' option.
+func newReport(opt *ListDisplayOptions) *Report {
+	r := &Report{}
+	if opt.Stats != "" {
+		r.stats = newStats(opt.StatsBy == "language")
+	}
+	return r
+}
+
+// add counts f except the roots of trees.
+func (r *Report) add(f FileInfo) {
+	if r.stats != nil {
+		r.stats.add(f)
+	}
+
+	p, ok := f.Parent()
+	if !ok || isVirtualDir(p) || IsOmitted(f) {
+		return
+	}
+
+	if f.IsDir() {
+		r.dirs++
+	} else {
+		r.files++
+	}
+}
+
+// Dirs returns the number of listed directories except the roots.
+func (r *Report) Dirs() int {
+	return r.dirs
+}
+
+// Files returns the number of listed files which aren't directories.
+func (r *Report) Files() int {
+	return r.files
+}
+
+func plural(n int, singular, plural string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, singular)
+	}
+	return fmt.Sprintf("%d %s", n, plural)
+}
+
+func (r *Report) String() string {
+	return plural(r.dirs, "directory", "directories") + ", " + plural(r.files, "file", "files")
+}
+
+// writeSummary writes the report by '--report' option and the statistics by '--stats' option after the trees.
+func writeSummary(w io.Writer, r *Report, opt *ListDisplayOptions) error {
+	if opt.IsReport() {
+		if _, err := fmt.Fprintf(w, "\n%s\n", r); err != nil {
+			return xerrors.Errorf("failed to write report: %w", err)
+		}
+	}
+
+	if r.stats != nil {
+		if _, err := fmt.Fprintln(w); err != nil {
+			return xerrors.Errorf("failed to write stats: %w", err)
+		}
+		return r.stats.write(w, opt)
+	}
+	return nil
+}
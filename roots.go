@@ -0,0 +1,74 @@
+package gtree
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// dedupRoots removes roots which are the same as or under another root.
+func dedupRoots(roots []string) []string {
+	abs := make([]string, len(roots))
+	for i, root := range roots {
+		a, err := filepath.Abs(root)
+		if err != nil {
+			a = filepath.Clean(root)
+		}
+		abs[i] = a
+	}
+
+	result := make([]string, 0, len(roots))
+	for i, root := range roots {
+		duplicated := false
+		for j := range roots {
+			if i == j {
+				continue
+			}
+
+			// The same roots are listed at the first one.
+			if abs[i] == abs[j] && j < i || isUnder(abs[i], abs[j]) {
+				duplicated = true
+				break
+			}
+		}
+
+		if !duplicated {
+			result = append(result, root)
+		}
+	}
+	return result
+}
+
+// isUnder returns true, when path is under dir.
+func isUnder(path, dir string) bool {
+	if dir == string(filepath.Separator) {
+		return path != dir && strings.HasPrefix(path, dir)
+	}
+	return strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+// commonDir returns the common parent directory of roots.
+func commonDir(roots []string) string {
+	var common []string
+	for i, root := range roots {
+		dir := filepath.Dir(filepath.Clean(root))
+		elems := strings.Split(filepath.ToSlash(dir), "/")
+		if i == 0 {
+			common = elems
+			continue
+		}
+
+		n := 0
+		for n < len(common) && n < len(elems) && common[n] == elems[n] {
+			n++
+		}
+		common = common[:n]
+	}
+
+	switch {
+	case len(common) == 0:
+		return "."
+	case len(common) == 1 && common[0] == "":
+		return "/"
+	}
+	return filepath.FromSlash(strings.Join(common, "/"))
+}
@@ -0,0 +1,108 @@
+package gtree
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDedupRoots(t *testing.T) {
+	tests := []struct {
+		roots  []string
+		expect []string
+	}{
+		{[]string{"a", "b"}, []string{"a", "b"}},
+		{[]string{"a", "a/b", "b"}, []string{"a", "b"}},
+		{[]string{"a/b", "a"}, []string{"a"}},
+		{[]string{"a", "./a/", "ab"}, []string{"a", "ab"}},
+		{[]string{"/", "/a"}, []string{"/"}},
+	}
+
+	for _, tt := range tests {
+		if got := dedupRoots(tt.roots); !reflect.DeepEqual(got, tt.expect) {
+			t.Errorf("dedupRoots(%v) expected %v, got %v", tt.roots, tt.expect, got)
+		}
+	}
+}
+
+func TestCommonDir(t *testing.T) {
+	tests := []struct {
+		roots  []string
+		expect string
+	}{
+		{[]string{"src", "docs"}, "."},
+		{[]string{"a/src", "a/docs"}, "a"},
+		{[]string{"a/b/src", "a/c/docs"}, "a"},
+		{[]string{"/a/b", "/a/c"}, "/a"},
+		{[]string{"/a", "/b"}, "/"},
+		{[]string{"/a/b", "c"}, "."},
+	}
+
+	for _, tt := range tests {
+		if got := commonDir(tt.roots); got != tt.expect {
+			t.Errorf("commonDir(%v) expected %s, got %s", tt.roots, tt.expect, got)
+		}
+	}
+}
+
+func TestShowTrees(t *testing.T) {
+	dir := newPruneTestDir(t)
+	defer os.RemoveAll(dir)
+
+	newOpts := func(combine bool) Options {
+		displayOptions := &ListDisplayOptions{NoIcons: []bool{true}, Report: []bool{true}}
+		if combine {
+			displayOptions.Combine = []bool{true}
+		}
+		return Options{
+			ListOptions: &ListOptions{
+				ListSearchOptions:  &ListSearchOptions{},
+				ListDisplayOptions: displayOptions,
+			},
+		}
+	}
+
+	a := filepath.Join(dir, "a")
+	e := filepath.Join(dir, "e")
+	roots := []string{a, e, filepath.Join(a, "b")}
+
+	tests := map[string]struct {
+		opts   Options
+		expect string
+	}{
+		"consecutive": {
+			opts: newOpts(false),
+			expect: a + "\n" +
+				"└── b\n" +
+				"    └── match.txt\n" +
+				e + "\n" +
+				"└── other.go\n" +
+				"\n1 directory, 2 files\n",
+		},
+		"combined": {
+			opts: newOpts(true),
+			expect: dir + "\n" +
+				"├── " + a + "\n" +
+				"│   └── b\n" +
+				"│       └── match.txt\n" +
+				"└── " + e + "\n" +
+				"    └── other.go\n" +
+				"\n1 directory, 2 files\n",
+		},
+	}
+
+	for key, tt := range tests {
+		t.Run(key, func(t *testing.T) {
+			buffer := new(bytes.Buffer)
+			if err := showTrees(buffer, roots, tt.opts); err != nil {
+				t.Fatalf("showTrees() returns error: %v", err)
+			}
+
+			if got := ansiEscape.ReplaceAllString(buffer.String(), ""); got != tt.expect {
+				t.Errorf("showTrees() expected '%s', got '%s'", tt.expect, got)
+			}
+		})
+	}
+}
@@ -1,9 +1,10 @@
-package main
+package gtree
 
 import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -26,18 +27,28 @@ func dirwalk(root FileInfo, ch chan<- FileInfo, depth int, listOptions *ListSear
 		return nil
 	}
 
+	if listOptions.IsPrune() || listOptions.IsAnnotatedOnly() {
+		return prunedDirwalk(root, ch, depth, listOptions)
+	}
+
 	files, err := ioutil.ReadDir(root.Path())
 	if err != nil {
 		root.SetError(err)
 		ch <- root
 		return nil
 	}
-	ch <- root
 
-	files = filterFiles(files, listOptions)
+	files = hideGenerated(root.Path(), filterFiles(files, listOptions), listOptions)
+	if exceedsFileLimit(len(files), listOptions) {
+		root.SetError(errExceedsFileLimit(len(files)))
+		ch <- root
+		return nil
+	}
+	ch <- root
 
+	files, omittedCount := headFiles(files, listOptions)
 	for i, file := range files {
-		isLast := i == len(files)-1
+		isLast := i == len(files)-1 && omittedCount == 0
 
 		child := NewFileInfo(file, root, isLast)
 
@@ -46,9 +57,30 @@ func dirwalk(root FileInfo, ch chan<- FileInfo, depth int, listOptions *ListSear
 			return err
 		}
 	}
+
+	if omittedCount > 0 {
+		ch <- newOmitted(root, omittedCount)
+	}
 	return nil
 }
 
+func errExceedsFileLimit(n int) error {
+	return fmt.Errorf("%d entries exceeds filelimit, not opening dir", n)
+}
+
+// exceedsFileLimit returns true, when the directory which has n entries isn't opened by '--filelimit' option.
+func exceedsFileLimit(n int, opts *ListSearchOptions) bool {
+	return opts.FileLimit > 0 && n > opts.FileLimit
+}
+
+// headFiles returns the first files by '--head' option, and the number of the omitted files.
+func headFiles(files []os.FileInfo, opts *ListSearchOptions) ([]os.FileInfo, int) {
+	if opts.Head <= 0 || len(files) <= opts.Head {
+		return files, 0
+	}
+	return files[:opts.Head], len(files) - opts.Head
+}
+
 // Remove files which don't satisfy options.
 func filterFiles(files []os.FileInfo, opts *ListSearchOptions) []os.FileInfo {
 	result := make([]os.FileInfo, 0)
@@ -65,11 +97,108 @@ func filterFiles(files []os.FileInfo, opts *ListSearchOptions) []os.FileInfo {
 			continue
 		}
 
+		if !f.IsDir() && len(opts.Patterns) != 0 && !matchPatterns(f.Name(), opts.Patterns) {
+			continue
+		}
+
+		if !f.IsDir() && opts.hasPredicate() && !opts.matchFile(f) {
+			continue
+		}
+
 		result = append(result, f)
 	}
 	return result
 }
 
+// scannedFile is a file read ahead of sending to decide whether its directory is pruned.
+type scannedFile struct {
+	os.FileInfo
+
+	children []*scannedFile
+	err      error
+}
+
+// prunedDirwalk sends root and its descendants except directories which have no listed descendants.
+// Since a directory is sent before its children, the whole tree under root is read ahead,
+// so that IsLast is computed from the pruned children.
+func prunedDirwalk(root FileInfo, ch chan<- FileInfo, depth int, listOptions *ListSearchOptions) error {
+	files, err := scanDir(root.Path(), relativePath(root), depth+1, listOptions)
+	if err != nil {
+		root.SetError(err)
+	}
+	ch <- root
+
+	sendScannedFiles(root, files, ch, listOptions)
+	return nil
+}
+
+func sendScannedFiles(parent FileInfo, files []*scannedFile, ch chan<- FileInfo, listOptions *ListSearchOptions) {
+	omittedCount := 0
+	if listOptions.Head > 0 && len(files) > listOptions.Head {
+		files, omittedCount = files[:listOptions.Head], len(files)-listOptions.Head
+	}
+
+	for i, file := range files {
+		isLast := i == len(files)-1 && omittedCount == 0
+
+		child := NewFileInfo(file.FileInfo, parent, isLast)
+		if file.err != nil {
+			child.SetError(file.err)
+		}
+		ch <- child
+
+		sendScannedFiles(child, file.children, ch, listOptions)
+	}
+
+	if omittedCount > 0 {
+		ch <- newOmitted(parent, omittedCount)
+	}
+}
+
+// scanDir reads dir and its descendants, and removes directories which have no listed descendants.
+// rel is the path of dir from the tree's root, and depth is the depth of files in dir.
+// Directories deeper than '-L' option are also read to decide whether their ancestors are pruned,
+// but their files aren't kept. Each directory is read only once.
+func scanDir(dir, rel string, depth int, opts *ListSearchOptions) ([]*scannedFile, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files = hideGenerated(dir, filterFiles(files, opts), opts)
+	if exceedsFileLimit(len(files), opts) {
+		return nil, errExceedsFileLimit(len(files))
+	}
+
+	result := make([]*scannedFile, 0)
+	for _, f := range files {
+		fileRel := f.Name()
+		if rel != "." {
+			fileRel = rel + "/" + f.Name()
+		}
+		_, annotated := opts.annotations[fileRel]
+
+		if !f.IsDir() {
+			if !opts.IsAnnotatedOnly() || annotated {
+				result = append(result, &scannedFile{FileInfo: f})
+			}
+			continue
+		}
+
+		// Keep unreadable directories, so that the errors are displayed.
+		path := dir + "/" + f.Name()
+		children, err := scanDir(path, fileRel, depth+1, opts)
+		if err != nil || len(children) != 0 || opts.matchDir(path, f) || (opts.IsAnnotatedOnly() && annotated) {
+			if opts.Level != nil && depth >= *opts.Level {
+				// The children are beyond the level, so the directory is listed without them and their errors.
+				children, err = nil, nil
+			}
+			result = append(result, &scannedFile{FileInfo: f, children: children, err: err})
+		}
+	}
+	return result, nil
+}
+
 func inString(s string, list []string) bool {
 	for _, l := range list {
 		if s == l {
@@ -78,3 +207,13 @@ func inString(s string, list []string) bool {
 	}
 	return false
 }
+
+// matchPatterns returns true, when s matches any of the wild-card patterns.
+func matchPatterns(s string, patterns []string) bool {
+	for _, p := range patterns {
+		if ok, _ := filepath.Match(p, s); ok {
+			return true
+		}
+	}
+	return false
+}
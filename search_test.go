@@ -1,8 +1,11 @@
-package main
+package gtree
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
@@ -146,6 +149,19 @@ func TestFilterFiles(t *testing.T) {
 				newDummySearchFileInfo(".sym-dotfolder", true, true),
 			},
 		},
+		{
+			&ListSearchOptions{
+				All:            []bool{},
+				OnlyDirectory:  []bool{},
+				IgnorePatterns: []string{},
+				Patterns:       []string{"sym-*"},
+			},
+			[]os.FileInfo{
+				newDummySearchFileInfo("sym-file", false, true),
+				newDummySearchFileInfo("folder", true, false),
+				newDummySearchFileInfo("sym-folder", true, true),
+			},
+		},
 	}
 
 	for i, in := range inputs {
@@ -156,3 +172,217 @@ func TestFilterFiles(t *testing.T) {
 	}
 
 }
+
+func newPruneTestDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "gtree")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// dir
+	// ├── a
+	// │   └── b
+	// │       └── match.txt
+	// ├── c
+	// │   └── d
+	// ├── e
+	// │   └── other.go
+	// └── top.txt
+	for _, d := range []string{"a/b", "c/d", "e"} {
+		if err := os.MkdirAll(filepath.Join(dir, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range []string{"a/b/match.txt", "e/other.go", "top.txt"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, f), []byte("match"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestScanDir(t *testing.T) {
+	dir := newPruneTestDir(t)
+	defer os.RemoveAll(dir)
+
+	var names func(files []*scannedFile) []string
+	names = func(files []*scannedFile) []string {
+		result := make([]string, 0, len(files))
+		for _, f := range files {
+			result = append(result, f.Name())
+			for _, c := range names(f.children) {
+				result = append(result, f.Name()+"/"+c)
+			}
+		}
+		return result
+	}
+
+	level, top := 2, 1
+	inputs := []struct {
+		opts   *ListSearchOptions
+		result []string
+	}{
+		{&ListSearchOptions{}, []string{"a", "a/b", "a/b/match.txt", "e", "e/other.go", "top.txt"}},
+		{&ListSearchOptions{IgnorePatterns: []string{"other.go"}}, []string{"a", "a/b", "a/b/match.txt", "top.txt"}},
+		{&ListSearchOptions{Patterns: []string{"*.go"}}, []string{"e", "e/other.go"}},
+		{&ListSearchOptions{Type: "d", Empty: []bool{true}}, []string{"c", "c/d"}},
+		// Directories aren't pruned at the level, when they have files beyond it.
+		{&ListSearchOptions{Level: &level}, []string{"a", "a/b", "e", "e/other.go", "top.txt"}},
+		{&ListSearchOptions{Level: &top}, []string{"a", "e", "top.txt"}},
+		{
+			&ListSearchOptions{AnnotatedOnly: []bool{true}, annotations: annotations{"a/b": "b", "top.txt": "top"}},
+			[]string{"a", "a/b", "top.txt"},
+		},
+	}
+
+	for i, in := range inputs {
+		in.opts.Prune = []bool{true}
+		if err := in.opts.parsePredicates(time.Now()); err != nil {
+			t.Fatal(err)
+		}
+
+		files, err := scanDir(dir, ".", 1, in.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res := names(files)
+		if !reflect.DeepEqual(res, in.result) {
+			t.Errorf("%d: scanDir expected %v, got %v", i, in.result, res)
+		}
+	}
+}
+
+func TestDirwalk_Prune(t *testing.T) {
+	dir := newPruneTestDir(t)
+	defer os.RemoveAll(dir)
+
+	f, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := NewFileInfoForBase(f, nil, filepath.Dir(dir)+"/", true)
+
+	ch := make(chan FileInfo)
+	go Dirwalk(root, ch, &ListSearchOptions{Prune: []bool{true}, Patterns: []string{"*.txt"}})
+
+	expect := dir + "\n" +
+		"├── a\n" +
+		"│   └── b\n" +
+		"│       └── match.txt\n" +
+		"└── top.txt\n"
+
+	p := NewPrinter(&ListDisplayOptions{NoIcons: []bool{true}})
+	buffer := new(bytes.Buffer)
+	for f := range ch {
+		p.Write(buffer, f)
+	}
+
+	if got := ansiEscape.ReplaceAllString(buffer.String(), ""); got != expect {
+		t.Errorf("Dirwalk expected '%s', got '%s'", expect, got)
+	}
+}
+
+func TestDirwalk_PruneAtLevel(t *testing.T) {
+	dir := newPruneTestDir(t)
+	defer os.RemoveAll(dir)
+
+	f, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := NewFileInfoForBase(f, nil, filepath.Dir(dir)+"/", true)
+
+	level := 1
+	ch := make(chan FileInfo)
+	go Dirwalk(root, ch, &ListSearchOptions{Prune: []bool{true}, Level: &level})
+
+	// a and e have files beyond the level, and only c is empty.
+	expect := dir + "\n" +
+		"├── a\n" +
+		"├── e\n" +
+		"└── top.txt\n"
+
+	p := NewPrinter(&ListDisplayOptions{NoIcons: []bool{true}})
+	buffer := new(bytes.Buffer)
+	for f := range ch {
+		p.Write(buffer, f)
+	}
+
+	if got := ansiEscape.ReplaceAllString(buffer.String(), ""); got != expect {
+		t.Errorf("Dirwalk expected '%s', got '%s'", expect, got)
+	}
+}
+
+func TestDirwalk_FileLimitAndHead(t *testing.T) {
+	dir := newPruneTestDir(t)
+	defer os.RemoveAll(dir)
+
+	f, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type line struct {
+		name   string
+		isLast bool
+		err    string
+	}
+
+	inputs := []struct {
+		opts   *ListSearchOptions
+		result []line
+	}{
+		{
+			&ListSearchOptions{FileLimit: 3},
+			[]line{{"root", true, "4 entries exceeds filelimit, not opening dir"}},
+		},
+		{
+			&ListSearchOptions{Head: 3, FileLimit: 4},
+			[]line{
+				{"root", true, ""},
+				{"a", false, ""},
+				{"b", true, ""},
+				{"match.txt", true, ""},
+				{"c", false, ""},
+				{"d", true, ""},
+				{"e", false, ""},
+				{"other.go", true, ""},
+				{"… 1 more entry", true, ""},
+			},
+		},
+		{
+			&ListSearchOptions{Head: 1, Prune: []bool{true}},
+			[]line{
+				{"root", true, ""},
+				{"a", false, ""},
+				{"b", true, ""},
+				{"match.txt", true, ""},
+				{"… 2 more entries", true, ""},
+			},
+		},
+	}
+
+	for i, in := range inputs {
+		root := NewFileInfoForBase(f, nil, filepath.Dir(dir)+"/", true)
+		ch := make(chan FileInfo)
+		go Dirwalk(root, ch, in.opts)
+
+		got := make([]line, 0)
+		for f := range ch {
+			name := f.Name()
+			if f == root {
+				name = "root"
+			}
+
+			var errMessage string
+			if f.Error() != nil {
+				errMessage = f.Error().Error()
+			}
+			got = append(got, line{name, f.IsLast(), errMessage})
+		}
+
+		if !reflect.DeepEqual(got, in.result) {
+			t.Errorf("%d: Dirwalk expected %v, got %v", i, in.result, got)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package gtree
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/xerrors"
+)
+
+// noFileType is the group of files which have no extension.
+const noFileType = "(none)"
+
+// specialGroups is the groups of files which aren't regular files, such as symlinks and named pipes.
+// They are grouped by the mode instead of the extension, and their sizes aren't counted.
+var specialGroups = []struct {
+	mode os.FileMode
+	name string
+}{
+	{os.ModeSymlink, "(symlink)"},
+	{os.ModeNamedPipe, "(pipe)"},
+	{os.ModeSocket, "(socket)"},
+	{os.ModeDevice, "(device)"},
+}
+
+// specialGroup returns the group of the file which isn't regular file.
+func specialGroup(mode os.FileMode) string {
+	for _, g := range specialGroups {
+		if mode&g.mode != 0 {
+			return g.name
+		}
+	}
+	return "(other)"
+}
+
+// statsGroup is the statistics of files which have the same type or language.
+type statsGroup struct {
+	Name  string `json:"name"`
+	Files int    `json:"files"`
+	Size  int64  `json:"size"`
+	Lines int    `json:"lines"`
+
+	// fileType is the extension used for the icon.
+	fileType string
+}
+
+// stats is the statistics of files grouped by the file type or the language.
+type stats struct {
+	byLanguage bool
+	groups     map[string]*statsGroup
+}
+
+func newStats(byLanguage bool) *stats {
+	return &stats{
+		byLanguage: byLanguage,
+		groups:     make(map[string]*statsGroup),
+	}
+}
+
+func (s *stats) groupName(f FileInfo) (string, string) {
+	if info, ok := osFileInfo(f); ok && !info.Mode().IsRegular() {
+		return specialGroup(info.Mode()), ""
+	}
+
+	fileType := f.FileType()
+	if fileType == f.Name() {
+		fileType = noFileType
+	}
+
+	if !s.byLanguage {
+		return fileType, fileType
+	}
+
+	if l, ok := LanguageName(fileType); ok {
+		return l, fileType
+	}
+	return "Other", ""
+}
+
+// add counts the file which isn't directory.
+func (s *stats) add(f FileInfo) {
+	if f.IsDir() || IsOmitted(f) || isVirtualDir(f) {
+		return
+	}
+
+	name, fileType := s.groupName(f)
+	g, ok := s.groups[name]
+	if !ok {
+		g = &statsGroup{Name: name, fileType: fileType}
+		s.groups[name] = g
+	}
+	g.Files++
+
+	info, ok := osFileInfo(f)
+	if ok && info.Mode().IsRegular() {
+		g.Size += info.Size()
+	}
+
+	// Only regular files on the disk are read, since the paths of other sources aren't the listed entries,
+	// and reading named pipes or devices can block forever.
+	if lines, counted := f.Lines(); counted {
+		g.Lines += lines
+	} else if ok && info.Mode().IsRegular() && f.Error() == nil {
+		if lines, ok, err := countLines(f.Path()); err == nil && ok {
+			g.Lines += lines
+		}
+	}
+}
+
+// sorted returns the groups sorted by the total size in descending order.
+func (s *stats) sorted() []*statsGroup {
+	result := make([]*statsGroup, 0, len(s.groups))
+	for _, g := range s.groups {
+		result = append(result, g)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Size != result[j].Size {
+			return result[i].Size > result[j].Size
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// write writes the statistics in the format by '--stats' option.
+func (s *stats) write(w io.Writer, opt *ListDisplayOptions) error {
+	switch opt.Stats {
+	case "json":
+		return s.writeJSON(w)
+	case "csv":
+		return s.writeCSV(w)
+	default:
+		return s.writeText(w, opt)
+	}
+}
+
+func (s *stats) header() []string {
+	if s.byLanguage {
+		return []string{"language", "files", "size", "lines"}
+	}
+	return []string{"type", "files", "size", "lines"}
+}
+
+// writeText writes the statistics as the table.
+// The names of groups are escaped like file names, since they come from the file names.
+func (s *stats) writeText(w io.Writer, opt *ListDisplayOptions) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "%s\t\n", strings.ToUpper(strings.Join(s.header(), "\t")))
+
+	for _, g := range s.sorted() {
+		name := opt.escape(g.Name, false)
+		if !opt.NoIcon() && g.fileType != "" {
+			name = NewIconString(g.fileType) + " " + name
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%d\t\n", name, g.Files, humanSize(g.Size), g.Lines)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return xerrors.Errorf("failed to write stats: %w", err)
+	}
+	return nil
+}
+
+func (s *stats) writeJSON(w io.Writer) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	if err := e.Encode(s.sorted()); err != nil {
+		return xerrors.Errorf("failed to write stats: %w", err)
+	}
+	return nil
+}
+
+func (s *stats) writeCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write(s.header())
+	for _, g := range s.sorted() {
+		cw.Write([]string{g.Name, strconv.Itoa(g.Files), strconv.FormatInt(g.Size, 10), strconv.Itoa(g.Lines)})
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return xerrors.Errorf("failed to write stats: %w", err)
+	}
+	return nil
+}
+
+// isStatsDocument returns true, when '--stats' option prints the statistics in json or csv,
+// which are read by programs, so that the trees aren't written before them.
+func isStatsDocument(opt *ListDisplayOptions) bool {
+	return opt.Stats == "json" || opt.Stats == "csv"
+}
+
+// statsFormatter is the Formatter which writes only the statistics instead of the trees.
+type statsFormatter struct {
+	opt *ListDisplayOptions
+}
+
+// Begin does nothing.
+func (f *statsFormatter) Begin(w io.Writer) error {
+	return nil
+}
+
+// EnterDir does nothing, since the directory is counted in the report.
+func (f *statsFormatter) EnterDir(w io.Writer, e *Entry) error {
+	return nil
+}
+
+// Entry does nothing, since the file is counted in the report.
+func (f *statsFormatter) Entry(w io.Writer, e *Entry) error {
+	return nil
+}
+
+// LeaveDir does nothing.
+func (f *statsFormatter) LeaveDir(w io.Writer, e *Entry) error {
+	return nil
+}
+
+// End writes the statistics of all trees.
+func (f *statsFormatter) End(w io.Writer, r *Report) error {
+	return r.stats.write(w, f.opt)
+}
+
+// humanSize returns the size such as '512B', '1.5K' and '20M'.
+func humanSize(size int64) string {
+	const units = "KMGTPE"
+	if size < 1024 {
+		return fmt.Sprintf("%dB", size)
+	}
+
+	s := float64(size) / 1024
+	i := 0
+	for s >= 1024 && i < len(units)-1 {
+		s /= 1024
+		i++
+	}
+	return fmt.Sprintf("%.1f%c", s, units[i])
+}
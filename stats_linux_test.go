@@ -0,0 +1,86 @@
+//go:build linux
+// +build linux
+
+package gtree
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestStats_SkipFIFO(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gtree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "fifo")
+	if err := syscall.Mkfifo(path, 0644); err != nil {
+		t.Skipf("mkfifo isn't supported: %v", err)
+	}
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := newStats(false)
+	done := make(chan struct{})
+	go func() {
+		s.add(NewFileInfoForBase(info, nil, dir+"/", true))
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the fifo was read")
+	}
+}
+
+func TestStats_Special(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gtree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeTestFiles(t, dir, map[string]string{
+		"a.go":       "package a\n",
+		"b.\x1b[31m": "b\n",
+	})
+	if err := os.Symlink("a.go", filepath.Join(dir, "link.go")); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Mkfifo(filepath.Join(dir, "fifo.go"), 0644); err != nil {
+		t.Skipf("mkfifo isn't supported: %v", err)
+	}
+
+	root, err := newRootFileInfo(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := newStats(false)
+	ch := make(chan FileInfo)
+	go Dirwalk(root, ch, &ListSearchOptions{})
+	for f := range ch {
+		s.add(f)
+	}
+
+	// The symlink and the named pipe aren't counted as go files, and the control character isn't written.
+	buffer := new(bytes.Buffer)
+	if err := s.write(buffer, &ListDisplayOptions{Stats: "text", NoIcons: []bool{true}}); err != nil {
+		t.Fatal(err)
+	}
+	expect := "       TYPE  FILES  SIZE  LINES\n         go      1   10B      1\n   \\x1b[31m      1    2B      1\n     (pipe)      1    0B      0\n  (symlink)      1    0B      0\n"
+	if got := buffer.String(); got != expect {
+		t.Errorf("stats.write() expected '%s', got '%s'", expect, got)
+	}
+}
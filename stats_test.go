@@ -0,0 +1,176 @@
+package gtree
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHumanSize(t *testing.T) {
+	tests := map[int64]string{
+		0:             "0B",
+		1023:          "1023B",
+		1536:          "1.5K",
+		20 * 1 << 20:  "20.0M",
+		3 * (1 << 30): "3.0G",
+	}
+
+	for size, expect := range tests {
+		if got := humanSize(size); got != expect {
+			t.Errorf("humanSize(%d) expected '%s', got '%s'", size, expect, got)
+		}
+	}
+}
+
+func TestStats(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gtree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for name, content := range map[string]string{
+		"a.go":     "package a\n\nfunc A() {}\n",
+		"b.go":     "package b\n",
+		"doc.md":   "# doc\n",
+		"Makefile": "all:\n",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	collect := func(byLanguage bool) *stats {
+		root, err := newRootFileInfo(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		s := newStats(byLanguage)
+		ch := make(chan FileInfo)
+		go Dirwalk(root, ch, &ListSearchOptions{})
+		for f := range ch {
+			s.add(f)
+		}
+		return s
+	}
+
+	tests := map[string]struct {
+		byLanguage bool
+		format     string
+		expect     string
+	}{
+		"csv by type": {
+			format: "csv",
+			expect: "type,files,size,lines\ngo,2,33,4\nmd,1,6,1\n(none),1,5,1\n",
+		},
+		"csv by language": {
+			byLanguage: true,
+			format:     "csv",
+			expect:     "language,files,size,lines\nGo,2,33,4\nMarkdown,1,6,1\nOther,1,5,1\n",
+		},
+		"text": {
+			format: "text",
+			expect: "    TYPE  FILES  SIZE  LINES\n      go      2   33B      4\n      md      1    6B      1\n  (none)      1    5B      1\n",
+		},
+		"json": {
+			format: "json",
+			expect: `[
+  {
+    "name": "go",
+    "files": 2,
+    "size": 33,
+    "lines": 4
+  },
+  {
+    "name": "md",
+    "files": 1,
+    "size": 6,
+    "lines": 1
+  },
+  {
+    "name": "(none)",
+    "files": 1,
+    "size": 5,
+    "lines": 1
+  }
+]
+`,
+		},
+	}
+
+	for key, tt := range tests {
+		t.Run(key, func(t *testing.T) {
+			buffer := new(bytes.Buffer)
+			if err := collect(tt.byLanguage).write(buffer, &ListDisplayOptions{Stats: tt.format, NoIcons: []bool{true}}); err != nil {
+				t.Fatalf("stats.write() unexpected error: %v", err)
+			}
+			if buffer.String() != tt.expect {
+				t.Errorf("stats.write() expected '%s', got '%s'", tt.expect, buffer.String())
+			}
+		})
+	}
+}
+
+func TestStats_NonDisk(t *testing.T) {
+	// The node has the path of the file on the disk, but it isn't the listed entry.
+	s := newStats(false)
+	s.add(NewNode("main.go", "go", false))
+
+	g := s.groups["go"]
+	if g == nil || g.Files != 1 || g.Size != 0 || g.Lines != 0 {
+		t.Errorf("stats.add() expected a file without size and lines, got %+v", g)
+	}
+}
+
+func TestShowTrees_StatsDocument(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gtree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeTestFiles(t, dir, map[string]string{
+		"a/b.go": "package b\n",
+	})
+
+	tests := map[string]string{
+		"csv":  "type,files,size,lines\ngo,1,10,1\n",
+		"text": "b.go",
+	}
+
+	for format, expect := range tests {
+		t.Run(format, func(t *testing.T) {
+			opts := Options{ListOptions: &ListOptions{
+				ListSearchOptions:  &ListSearchOptions{},
+				ListDisplayOptions: &ListDisplayOptions{Stats: format, NoIcons: []bool{true}, Indent: 4},
+			}}
+			if err := validateOptions(opts); err != nil {
+				t.Fatal(err)
+			}
+
+			buffer := new(bytes.Buffer)
+			if err := showTrees(buffer, []string{filepath.Join(dir, "a")}, opts); err != nil {
+				t.Fatal(err)
+			}
+
+			// The csv document isn't preceded by the tree, so that programs can read it.
+			got := buffer.String()
+			if format == "csv" && got != expect || format == "text" && !strings.Contains(got, expect) {
+				t.Errorf("showTrees() with '--stats=%s' expected '%s', got '%s'", format, expect, got)
+			}
+		})
+	}
+}
+
+func TestValidateOptions_StatsDocument(t *testing.T) {
+	opts := Options{ListOptions: &ListOptions{
+		ListSearchOptions:  &ListSearchOptions{},
+		ListDisplayOptions: &ListDisplayOptions{Stats: "json", Report: []bool{true}, Indent: 4},
+	}}
+	if err := validateOptions(opts); err == nil {
+		t.Errorf("validateOptions() expected error for report with json stats")
+	}
+}
@@ -0,0 +1,61 @@
+package gtree
+
+import (
+	"os"
+
+	"github.com/gookit/color"
+	"golang.org/x/term"
+)
+
+// isTerminal returns true, when f is a terminal.
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// useColor decides whether to color the output.
+// In auto mode, colors are used for terminals,
+// CLICOLOR_FORCE forces colors, and NO_COLOR disables colors.
+func useColor(mode string, terminal bool, getenv func(string) string) bool {
+	switch mode {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+
+	if v := getenv("CLICOLOR_FORCE"); v != "" && v != "0" {
+		return true
+	}
+
+	if getenv("NO_COLOR") != "" {
+		return false
+	}
+	return terminal
+}
+
+// useIcons decides whether to show icons.
+// In auto mode, icons are shown for terminals.
+func useIcons(mode string, terminal bool) bool {
+	switch mode {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	return terminal
+}
+
+// applyOutputPolicy enables or disables colors and icons for the output.
+func applyOutputPolicy(opt *ListDisplayOptions, terminal bool) {
+	if useColor(opt.Color, terminal, os.Getenv) {
+		color.Enable = true
+		// Colors are forced, even if TERM doesn't look like supporting them.
+		if !terminal || opt.Color == "always" {
+			color.ForceOpenColor()
+		}
+	} else {
+		color.Enable = false
+	}
+
+	opt.noIcon = !useIcons(opt.Icons, terminal)
+}
@@ -0,0 +1,53 @@
+package gtree
+
+import "testing"
+
+func TestUseColor(t *testing.T) {
+	tests := map[string]struct {
+		mode     string
+		terminal bool
+		env      map[string]string
+		expect   bool
+	}{
+		"auto terminal":             {"auto", true, nil, true},
+		"auto pipe":                 {"auto", false, nil, false},
+		"auto NO_COLOR":             {"auto", true, map[string]string{"NO_COLOR": "1"}, false},
+		"auto CLICOLOR_FORCE":       {"auto", false, map[string]string{"CLICOLOR_FORCE": "1"}, true},
+		"auto CLICOLOR_FORCE=0":     {"auto", false, map[string]string{"CLICOLOR_FORCE": "0"}, false},
+		"auto both":                 {"auto", false, map[string]string{"CLICOLOR_FORCE": "1", "NO_COLOR": "1"}, true},
+		"always pipe":               {"always", false, nil, true},
+		"always NO_COLOR":           {"always", false, map[string]string{"NO_COLOR": "1"}, true},
+		"never terminal":            {"never", true, nil, false},
+		"never with CLICOLOR_FORCE": {"never", true, map[string]string{"CLICOLOR_FORCE": "1"}, false},
+	}
+
+	for key, tt := range tests {
+		t.Run(key, func(t *testing.T) {
+			getenv := func(k string) string {
+				return tt.env[k]
+			}
+			if got := useColor(tt.mode, tt.terminal, getenv); got != tt.expect {
+				t.Errorf("useColor() expected %v, got %v", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestUseIcons(t *testing.T) {
+	tests := []struct {
+		mode     string
+		terminal bool
+		expect   bool
+	}{
+		{"auto", true, true},
+		{"auto", false, false},
+		{"always", false, true},
+		{"never", true, false},
+	}
+
+	for _, tt := range tests {
+		if got := useIcons(tt.mode, tt.terminal); got != tt.expect {
+			t.Errorf("useIcons(%s, %v) expected %v, got %v", tt.mode, tt.terminal, tt.expect, got)
+		}
+	}
+}
@@ -0,0 +1,378 @@
+package gtree
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+	"golang.org/x/xerrors"
+)
+
+// tuiNode is a file in the interactive mode.
+// Children are read when the directory is expanded at first.
+type tuiNode struct {
+	file     FileInfo
+	depth    int
+	children []*tuiNode
+	loaded   bool
+	expanded bool
+}
+
+// tui is the state of the interactive mode.
+type tui struct {
+	root          *tuiNode
+	searchOptions *ListSearchOptions
+	printer       *Printer
+
+	rows   []*tuiNode
+	cursor int
+	offset int
+
+	filter    string
+	filtering bool
+
+	// selected is the path which is printed on exit.
+	selected string
+}
+
+func newTUI(root FileInfo, opts Options) *tui {
+	t := &tui{
+		root:          &tuiNode{file: root},
+		searchOptions: opts.ListOptions.ListSearchOptions,
+		printer:       NewPrinter(opts.ListOptions.ListDisplayOptions),
+	}
+	t.expand(t.root)
+	t.refresh()
+	return t
+}
+
+// load reads the children of n through Dirwalk.
+func (t *tui) load(n *tuiNode) {
+	if n.loaded || !n.file.IsDir() {
+		return
+	}
+	n.loaded = true
+
+	// Pruning needs to read ahead the whole subtree, so the level can't be limited.
+	opts := *t.searchOptions
+	if !opts.IsPrune() {
+		level := 1
+		opts.Level = &level
+	}
+
+	ch := make(chan FileInfo)
+	go Dirwalk(n.file, ch, &opts)
+	for f := range ch {
+		if p, ok := f.Parent(); ok && p == n.file {
+			n.children = append(n.children, &tuiNode{file: f, depth: n.depth + 1})
+		}
+	}
+}
+
+func (t *tui) expand(n *tuiNode) {
+	if !n.file.IsDir() {
+		return
+	}
+
+	if level := t.searchOptions.Level; level != nil && n.depth >= *level {
+		return
+	}
+
+	t.load(n)
+	n.expanded = true
+}
+
+// refresh updates the visible rows.
+func (t *tui) refresh() {
+	t.rows = t.visibleRows(t.root, t.rows[:0])
+
+	if t.cursor >= len(t.rows) {
+		t.cursor = len(t.rows) - 1
+	}
+	if t.cursor < 0 {
+		t.cursor = 0
+	}
+}
+
+func (t *tui) visibleRows(n *tuiNode, rows []*tuiNode) []*tuiNode {
+	start := len(rows)
+	rows = append(rows, n)
+
+	if n.expanded {
+		for _, c := range n.children {
+			rows = t.visibleRows(c, rows)
+		}
+	}
+
+	// Keep the ancestors of matched files.
+	if t.filter != "" && len(rows) == start+1 && !fuzzyMatch(t.filter, n.file.Name()) && n != t.root {
+		rows = rows[:start]
+	}
+	return rows
+}
+
+// fuzzyMatch returns true, when s contains the characters of pattern in order ignoring case.
+func fuzzyMatch(pattern, s string) bool {
+	s = strings.ToLower(s)
+	for _, r := range strings.ToLower(pattern) {
+		i := strings.IndexRune(s, r)
+		if i < 0 {
+			return false
+		}
+		s = s[i+utf8.RuneLen(r):]
+	}
+	return true
+}
+
+func (t *tui) current() *tuiNode {
+	if len(t.rows) == 0 {
+		return nil
+	}
+	return t.rows[t.cursor]
+}
+
+func (t *tui) parentRow(n *tuiNode) int {
+	p, ok := n.file.Parent()
+	if !ok {
+		return -1
+	}
+	for i, r := range t.rows {
+		if r.file == p {
+			return i
+		}
+	}
+	return -1
+}
+
+// handleKey updates the state by the key, and returns true when the interactive mode finishes.
+func (t *tui) handleKey(key string) bool {
+	if t.filtering {
+		switch key {
+		case "enter":
+			t.filtering = false
+			return false
+		case "esc":
+			t.filtering = false
+			t.filter = ""
+			t.refresh()
+			return false
+		case "backspace":
+			if t.filter != "" {
+				_, size := utf8.DecodeLastRuneInString(t.filter)
+				t.filter = t.filter[:len(t.filter)-size]
+				t.refresh()
+			}
+			return false
+		case "space":
+			t.filter += " "
+			t.refresh()
+			return false
+		case "up", "down", "left", "right", "ctrl-c", "":
+		default:
+			t.filter += key
+			t.refresh()
+			return false
+		}
+	}
+
+	n := t.current()
+	switch key {
+	case "up", "k":
+		if t.cursor > 0 {
+			t.cursor--
+		}
+	case "down", "j":
+		if t.cursor < len(t.rows)-1 {
+			t.cursor++
+		}
+	case "right", "l":
+		if n != nil {
+			t.expand(n)
+		}
+	case "left", "h":
+		if n == nil {
+			break
+		}
+		if n.expanded {
+			n.expanded = false
+		} else if i := t.parentRow(n); i >= 0 {
+			t.cursor = i
+		}
+	case "enter", "space":
+		if n == nil {
+			break
+		}
+		if n.expanded {
+			n.expanded = false
+		} else {
+			t.expand(n)
+		}
+	case "/":
+		t.filtering = true
+	case "o":
+		if n != nil {
+			t.selected = n.file.Path()
+		}
+		return true
+	case "q", "esc", "ctrl-c":
+		return true
+	}
+
+	t.refresh()
+	return false
+}
+
+// render draws the visible rows which fit in the height and the status line.
+func (t *tui) render(w io.Writer, height int) error {
+	lines := height - 1
+	if lines < 1 {
+		lines = 1
+	}
+
+	if t.cursor < t.offset {
+		t.offset = t.cursor
+	}
+	if t.cursor >= t.offset+lines {
+		t.offset = t.cursor - lines + 1
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("\x1b[H\x1b[2J")
+
+	for i := t.offset; i < len(t.rows) && i < t.offset+lines; i++ {
+		if i == t.cursor {
+			buf.WriteString("❯ ")
+		} else {
+			buf.WriteString("  ")
+		}
+
+		var row bytes.Buffer
+		if err := t.printer.Write(&row, t.rows[i].file); err != nil {
+			return xerrors.Errorf("failed to write row: %w", err)
+		}
+		buf.Write(bytes.TrimRight(row.Bytes(), "\n"))
+		buf.WriteString("\r\n")
+	}
+
+	fmt.Fprintf(&buf, "\x1b[%d;1H", height)
+	if t.filtering || t.filter != "" {
+		buf.WriteString("/" + t.filter)
+	} else {
+		buf.WriteString("↑↓ move  ⏎ expand/collapse  / filter  o select  q quit")
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// readKey reads a key press from the terminal in raw mode.
+func readKey(r *bufio.Reader) (string, error) {
+	c, _, err := r.ReadRune()
+	if err != nil {
+		return "", err
+	}
+
+	switch c {
+	case '\r', '\n':
+		return "enter", nil
+	case ' ':
+		return "space", nil
+	case 0x7f, '\b':
+		return "backspace", nil
+	case 0x03:
+		return "ctrl-c", nil
+	case 0x1b:
+		if r.Buffered() == 0 {
+			return "esc", nil
+		}
+		seq := make([]byte, 2)
+		if _, err := io.ReadFull(r, seq); err != nil {
+			return "", err
+		}
+		if seq[0] == '[' || seq[0] == 'O' {
+			switch seq[1] {
+			case 'A':
+				return "up", nil
+			case 'B':
+				return "down", nil
+			case 'C':
+				return "right", nil
+			case 'D':
+				return "left", nil
+			}
+		}
+		return "", nil
+	}
+
+	if !unicode.IsPrint(c) {
+		return "", nil
+	}
+	return string(c), nil
+}
+
+// runInteractive opens the full-screen interactive mode on the terminal.
+// The terminal is used instead of stdin and stdout,
+// so that the selected path can be passed to the shell such as `cd $(gtree -i -d)`.
+func runInteractive(root string, opts Options) error {
+	rootFile, err := newRootFileInfo(root)
+	if err != nil {
+		return err
+	}
+
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return xerrors.Errorf("failed to open terminal: %w", err)
+	}
+	defer tty.Close()
+
+	fd := int(tty.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return xerrors.Errorf("failed to make terminal raw: %w", err)
+	}
+
+	t := newTUI(rootFile, opts)
+	err = t.loop(tty, fd)
+	term.Restore(fd, state)
+	if err != nil {
+		return err
+	}
+
+	if t.selected != "" {
+		fmt.Println(t.selected)
+	}
+	return nil
+}
+
+func (t *tui) loop(tty *os.File, fd int) error {
+	// Use the alternate screen, hide the cursor and disable line wrapping while running.
+	fmt.Fprint(tty, "\x1b[?1049h\x1b[?25l\x1b[?7l")
+	defer fmt.Fprint(tty, "\x1b[?7h\x1b[?25h\x1b[?1049l")
+
+	r := bufio.NewReader(tty)
+	for {
+		_, height, err := term.GetSize(fd)
+		if err != nil {
+			return xerrors.Errorf("failed to get terminal size: %w", err)
+		}
+
+		if err := t.render(tty, height); err != nil {
+			return err
+		}
+
+		key, err := readKey(r)
+		if err != nil {
+			return xerrors.Errorf("failed to read key: %w", err)
+		}
+
+		if t.handleKey(key) {
+			return nil
+		}
+	}
+}
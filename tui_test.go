@@ -0,0 +1,100 @@
+package gtree
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		s       string
+		expect  bool
+	}{
+		{"", "main.go", true},
+		{"mgo", "main.go", true},
+		{"MAIN", "main.go", true},
+		{"gm", "main.go", false},
+		{"テスト", "テスト.txt", true},
+	}
+
+	for _, tt := range tests {
+		if got := fuzzyMatch(tt.pattern, tt.s); got != tt.expect {
+			t.Errorf("fuzzyMatch(%s, %s) expected %v, got %v", tt.pattern, tt.s, tt.expect, got)
+		}
+	}
+}
+
+func TestReadKey(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("\x1b[A\x1b[Bj\r /\x7f\x03"))
+	expect := []string{"up", "down", "j", "enter", "space", "/", "backspace", "ctrl-c"}
+
+	for _, e := range expect {
+		key, err := readKey(r)
+		if err != nil {
+			t.Fatalf("readKey() returns error: %v", err)
+		}
+		if key != e {
+			t.Errorf("readKey() expected %s, got %s", e, key)
+		}
+	}
+}
+
+func TestTUI_HandleKey(t *testing.T) {
+	dir := newPruneTestDir(t)
+	defer os.RemoveAll(dir)
+
+	root, err := newRootFileInfo(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := Options{
+		ListOptions: &ListOptions{
+			ListSearchOptions:  &ListSearchOptions{},
+			ListDisplayOptions: &ListDisplayOptions{NoIcons: []bool{true}},
+		},
+	}
+	tu := newTUI(root, opts)
+
+	names := func() []string {
+		result := make([]string, 0, len(tu.rows))
+		for _, r := range tu.rows[1:] {
+			result = append(result, r.file.Name())
+		}
+		return result
+	}
+
+	if expect := []string{"a", "c", "e", "top.txt"}; !reflect.DeepEqual(names(), expect) {
+		t.Errorf("initial rows expected %v, got %v", expect, names())
+	}
+
+	// Expand 'a'.
+	for _, key := range []string{"down", "enter"} {
+		tu.handleKey(key)
+	}
+	if expect := []string{"a", "b", "c", "e", "top.txt"}; !reflect.DeepEqual(names(), expect) {
+		t.Errorf("expanded rows expected %v, got %v", expect, names())
+	}
+
+	// Filter by 'tx'.
+	for _, key := range []string{"/", "t", "x", "enter"} {
+		tu.handleKey(key)
+	}
+	if expect := []string{"top.txt"}; !reflect.DeepEqual(names(), expect) {
+		t.Errorf("filtered rows expected %v, got %v", expect, names())
+	}
+
+	// Select 'top.txt'.
+	tu.handleKey("down")
+	if !tu.handleKey("o") {
+		t.Fatalf("handleKey(o) expected to finish")
+	}
+	if expect := filepath.Join(dir, "top.txt"); tu.selected != expect {
+		t.Errorf("selected expected %s, got %s", expect, tu.selected)
+	}
+}
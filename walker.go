@@ -0,0 +1,199 @@
+package gtree
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// Walker is the source of trees.
+// Walk returns the channel which receives the root and its descendants in the same order as Dirwalk,
+// and closes it at the end. Errors of each file are set by SetError,
+// and the error which prevents the walk such as the missing root is returned.
+// The tree built in memory with NewNode can be sent by NodeTree.
+type Walker interface {
+	Walk(root string, opts *ListSearchOptions) (<-chan FileInfo, error)
+}
+
+// WalkerFunc is the function used as Walker.
+type WalkerFunc func(root string, opts *ListSearchOptions) (<-chan FileInfo, error)
+
+// Walk calls w.
+func (w WalkerFunc) Walk(root string, opts *ListSearchOptions) (<-chan FileInfo, error) {
+	return w(root, opts)
+}
+
+// defaultSource is the source of trees when '--source' option isn't specified.
+const defaultSource = "disk"
+
+// walkers is the sources of trees selected by '--source' option.
+var walkers = map[string]Walker{
+	"disk":    WalkerFunc(walkDisk),
+	"paths":   WalkerFunc(walkPathList),
+	"archive": WalkerFunc(walkArchive),
+	"data":    WalkerFunc(walkData),
+}
+
+// RegisterWalker adds the source of trees which is selected by '--source name'.
+// This is called by programs which use this package before Run, such as to list the objects of a bucket.
+// The source whose name is already registered is replaced.
+// Since the files of registered sources may have no metadata, size, time, type and empty options can't be used with them.
+func RegisterWalker(name string, w Walker) {
+	walkers[name] = w
+}
+
+// walkerOf returns the registered source of trees.
+// The empty name is the default source.
+func walkerOf(name string) (Walker, bool) {
+	if name == "" {
+		name = defaultSource
+	}
+	w, ok := walkers[name]
+	return w, ok
+}
+
+func isDefaultSource(name string) bool {
+	return name == "" || name == defaultSource
+}
+
+// hasMetadata returns true, when the files of the source have the metadata such as sizes and modes.
+func hasMetadata(name string) bool {
+	return isDefaultSource(name) || name == "archive"
+}
+
+// sourceNames returns the names of the registered sources.
+func sourceNames() []string {
+	names := make([]string, 0, len(walkers))
+	for name := range walkers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// walkDisk walks the directory on the local disk.
+func walkDisk(root string, opts *ListSearchOptions) (<-chan FileInfo, error) {
+	rootFile, err := newRootFileInfo(root)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan FileInfo)
+	go Dirwalk(rootFile, ch, opts)
+	return ch, nil
+}
+
+// walkData walks the JSON, YAML or TOML document.
+func walkData(root string, opts *ListSearchOptions) (<-chan FileInfo, error) {
+	tree, err := loadData(root)
+	if err != nil {
+		return nil, err
+	}
+	return NodeTree(tree, opts), nil
+}
+
+// walkPathList walks the list of paths in the file, one path per line.
+// The list is read from stdin when root is '-'.
+func walkPathList(root string, opts *ListSearchOptions) (<-chan FileInfo, error) {
+	var r io.Reader = os.Stdin
+	if root != "-" {
+		f, err := os.Open(root)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to open path list: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	tree, err := readPathList(root, r)
+	if err != nil {
+		return nil, err
+	}
+	return NodeTree(tree, opts), nil
+}
+
+// readPathList returns the tree of the paths in r.
+// Paths which end with '/' or have descendants are directories.
+func readPathList(name string, r io.Reader) (*Node, error) {
+	b := newPathTree(name)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		path := strings.TrimSpace(scanner.Text())
+		if path == "" {
+			continue
+		}
+		b.add(path, strings.HasSuffix(path, "/"))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, xerrors.Errorf("failed to read path list: %w", err)
+	}
+	return b.tree(), nil
+}
+
+// pathTree builds the tree of nodes from slash-separated paths.
+type pathTree struct {
+	root *Node
+
+	// nodes is the added nodes keyed by the cleaned path.
+	nodes map[string]*Node
+}
+
+func newPathTree(name string) *pathTree {
+	return &pathTree{
+		root:  NewNode(name, "", true),
+		nodes: make(map[string]*Node),
+	}
+}
+
+// add adds the node of path and its ancestors, and returns the node of path.
+func (b *pathTree) add(path string, isDir bool) *Node {
+	path = strings.Trim(strings.TrimPrefix(path, "./"), "/")
+	if path == "" || path == "." {
+		return b.root
+	}
+
+	parent := b.root
+	elems := strings.Split(path, "/")
+	for i, e := range elems {
+		key := strings.Join(elems[:i+1], "/")
+		n, ok := b.nodes[key]
+		if !ok {
+			n = parent.Add(NewNode(e, fileTypeOf(e), true))
+			b.nodes[key] = n
+		}
+		parent = n
+	}
+
+	if isDir {
+		parent.fileType = ""
+	} else if len(parent.children) == 0 {
+		parent.isDir = false
+	}
+	return parent
+}
+
+// tree returns the root whose descendants are sorted by the name.
+// Nodes which have children are directories.
+func (b *pathTree) tree() *Node {
+	sortNodes(b.root)
+	return b.root
+}
+
+func sortNodes(n *Node) {
+	if len(n.children) != 0 {
+		n.isDir = true
+		n.fileType = ""
+	}
+
+	sort.SliceStable(n.children, func(i, j int) bool {
+		return n.children[i].name < n.children[j].name
+	})
+	for _, c := range n.children {
+		sortNodes(c)
+	}
+}
@@ -0,0 +1,216 @@
+package gtree
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeNodeTree writes the tree with Printer without icons and colors.
+func writeNodeTree(t *testing.T, root *Node, opts *ListSearchOptions) string {
+	p := NewPrinter(&ListDisplayOptions{NoIcons: []bool{true}})
+
+	buffer := new(bytes.Buffer)
+	for f := range NodeTree(root, opts) {
+		if err := p.Write(buffer, f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return ansiEscape.ReplaceAllString(buffer.String(), "")
+}
+
+func TestReadPathList(t *testing.T) {
+	list := "src/main.go\n./src/lib/\n\nREADME.md\nsrc/cmd/gtree/main.go\n/docs/\n"
+
+	root, err := readPathList("-", strings.NewReader(list))
+	if err != nil {
+		t.Fatalf("readPathList() unexpected error: %v", err)
+	}
+
+	expect := "-\n" +
+		"├── README.md\n" +
+		"├── docs\n" +
+		"└── src\n" +
+		"    ├── cmd\n" +
+		"    │   └── gtree\n" +
+		"    │       └── main.go\n" +
+		"    ├── lib\n" +
+		"    └── main.go\n"
+	if got := writeNodeTree(t, root, &ListSearchOptions{}); got != expect {
+		t.Errorf("readPathList() expected '%s', got '%s'", expect, got)
+	}
+
+	for _, path := range []string{"docs", "src/lib", "src/cmd"} {
+		n := root
+		for _, e := range strings.Split(path, "/") {
+			for _, c := range n.children {
+				if c.name == e {
+					n = c
+				}
+			}
+		}
+		if !n.IsDir() || n.FileType() != "" {
+			t.Errorf("readPathList() expected %s is directory", path)
+		}
+	}
+}
+
+func TestReadArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gtree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	entries := []struct {
+		name    string
+		content string
+	}{
+		{"src/", ""},
+		{"src/main.go", "package main\n"},
+		{"src/empty.txt", ""},
+		{"README.md", "# gtree\n"},
+	}
+
+	zipPath := filepath.Join(dir, "a.zip")
+	zf, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(zf)
+	for _, e := range entries {
+		w, err := zw.Create(e.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(w, e.content)
+	}
+	zw.Close()
+	zf.Close()
+
+	tgzPath := filepath.Join(dir, "a.tar.gz")
+	tf, err := os.Create(tgzPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gw := gzip.NewWriter(tf)
+	tw := tar.NewWriter(gw)
+	for _, e := range entries {
+		h := &tar.Header{Name: e.name, Mode: 0644, Size: int64(len(e.content)), Typeflag: tar.TypeReg}
+		if strings.HasSuffix(e.name, "/") {
+			h.Mode, h.Typeflag = 0755, tar.TypeDir
+		}
+		if err := tw.WriteHeader(h); err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(tw, e.content)
+	}
+	tw.Close()
+	gw.Close()
+	tf.Close()
+
+	for _, path := range []string{zipPath, tgzPath} {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			root, err := readArchive(path)
+			if err != nil {
+				t.Fatalf("readArchive() unexpected error: %v", err)
+			}
+
+			expect := path + "\n├── README.md\n└── src\n    ├── empty.txt\n    └── main.go\n"
+			if got := writeNodeTree(t, root, &ListSearchOptions{}); got != expect {
+				t.Errorf("readArchive() expected '%s', got '%s'", expect, got)
+			}
+
+			// Predicates are applied to the entries.
+			opts := &ListSearchOptions{Empty: []bool{true}}
+			if err := opts.parsePredicates(time.Now()); err != nil {
+				t.Fatal(err)
+			}
+			expect = path + "\n└── src\n    └── empty.txt\n"
+			if got := writeNodeTree(t, root, opts); got != expect {
+				t.Errorf("readArchive() with predicates expected '%s', got '%s'", expect, got)
+			}
+		})
+	}
+
+	if _, err := readArchive(filepath.Join(dir, "a.rar")); err == nil {
+		t.Errorf("readArchive() expected error for unknown format")
+	}
+}
+
+func TestRegisterWalker(t *testing.T) {
+	RegisterWalker("memory", WalkerFunc(func(root string, opts *ListSearchOptions) (<-chan FileInfo, error) {
+		tree := NewNode(root, "", true)
+		pods := tree.Add(NewNode("pods", "", true))
+		pods.Add(NewNode("web-1", "", false))
+		pods.Add(NewNode("web-2", "", false))
+		tree.Add(NewNode("services", "", true)).Add(NewNode("web", "", false))
+		return NodeTree(tree, opts), nil
+	}))
+	defer delete(walkers, "memory")
+
+	opts := Options{
+		ListOptions: &ListOptions{
+			ListSearchOptions:  &ListSearchOptions{Source: "memory", Patterns: []string{"web-*"}, Prune: []bool{true}},
+			ListDisplayOptions: &ListDisplayOptions{NoIcons: []bool{true}, Report: []bool{true}, Lines: []bool{true}, Indent: 4},
+		},
+	}
+	if err := validateOptions(opts); err != nil {
+		t.Fatalf("validateOptions() unexpected error: %v", err)
+	}
+
+	buffer := new(bytes.Buffer)
+	if err := showTrees(buffer, []string{"cluster"}, opts); err != nil {
+		t.Fatalf("showTrees() unexpected error: %v", err)
+	}
+
+	expect := "[      0] cluster\n" +
+		"└── [      0] pods\n" +
+		"    ├── [      -] web-1\n" +
+		"    └── [      -] web-2\n" +
+		"\n1 directory, 2 files\n"
+	if got := ansiEscape.ReplaceAllString(buffer.String(), ""); got != expect {
+		t.Errorf("showTrees() expected '%s', got '%s'", expect, got)
+	}
+}
+
+func TestValidateOptions_Source(t *testing.T) {
+	tests := map[string]struct {
+		search  *ListSearchOptions
+		display *ListDisplayOptions
+		source  string
+		err     bool
+	}{
+		"default":          {&ListSearchOptions{}, &ListDisplayOptions{Indent: 4}, "", false},
+		"archive":          {&ListSearchOptions{Source: "archive"}, &ListDisplayOptions{Indent: 4}, "archive", false},
+		"data option":      {&ListSearchOptions{Source: "disk", Data: []bool{true}}, &ListDisplayOptions{Indent: 4}, "data", false},
+		"data and paths":   {&ListSearchOptions{Source: "paths", Data: []bool{true}}, &ListDisplayOptions{Indent: 4}, "paths", true},
+		"unknown":          {&ListSearchOptions{Source: "s3"}, &ListDisplayOptions{Indent: 4}, "s3", true},
+		"watch on archive": {&ListSearchOptions{Source: "archive"}, &ListDisplayOptions{Indent: 4, Watch: []bool{true}}, "archive", true},
+		"size on archive":  {&ListSearchOptions{Source: "archive", MinSize: "1k"}, &ListDisplayOptions{Indent: 4}, "archive", false},
+		"size on paths":    {&ListSearchOptions{Source: "paths", MinSize: "1k"}, &ListDisplayOptions{Indent: 4}, "paths", true},
+		"empty on data":    {&ListSearchOptions{Source: "data", Empty: []bool{true}}, &ListDisplayOptions{Indent: 4}, "data", true},
+		"generated on tar": {&ListSearchOptions{Source: "archive", HideGenerated: []bool{true}}, &ListDisplayOptions{Indent: 4}, "archive", true},
+	}
+
+	for key, tt := range tests {
+		t.Run(key, func(t *testing.T) {
+			opts := Options{ListOptions: &ListOptions{ListSearchOptions: tt.search, ListDisplayOptions: tt.display}}
+			err := validateOptions(opts)
+			if (err != nil) != tt.err {
+				t.Fatalf("validateOptions() expected error %v, got %v", tt.err, err)
+			}
+			if !tt.err && tt.search.Source != tt.source {
+				t.Errorf("validateOptions() expected source '%s', got '%s'", tt.source, tt.search.Source)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package gtree
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// watchDebounce is the quiet period to wait for the following changes before redrawing.
+const watchDebounce = 100 * time.Millisecond
+
+// watcher notifies changes in the watched directories.
+type watcher interface {
+	// Add starts watching the directory.
+	Add(path string) error
+
+	// Remove stops watching the directory.
+	Remove(path string) error
+
+	// Watched returns the watched directories.
+	Watched() []string
+
+	// Events returns the channel which receives the changed paths.
+	Events() <-chan string
+
+	// Errors returns the channel which receives the errors while watching.
+	Errors() <-chan error
+
+	// Close stops watching all directories.
+	Close() error
+}
+
+// fileState is used to find files changed since the previous frame.
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+func stateOf(f FileInfo) fileState {
+	info, ok := osFileInfo(f)
+	if !ok || f.IsDir() {
+		return fileState{}
+	}
+	return fileState{size: info.Size(), modTime: info.ModTime()}
+}
+
+// highlighter is the Formatter which highlights the files changed since the previous frame.
+type highlighter interface {
+	SetHighlights(highlights map[string]bool)
+}
+
+// frame is the files of the trees drawn at once.
+type frame struct {
+	files  [][]FileInfo
+	states map[string]fileState
+}
+
+func newFrame(roots []string, opts Options) (*frame, error) {
+	f := &frame{states: make(map[string]fileState)}
+	for _, root := range roots {
+		rootFile, err := newRootFileInfo(root)
+		if err != nil {
+			return nil, err
+		}
+
+		files := make([]FileInfo, 0)
+		for file := range walkTree(rootFile, opts) {
+			files = append(files, file)
+			f.states[file.Path()] = stateOf(file)
+		}
+		f.files = append(f.files, files)
+	}
+	return f, nil
+}
+
+// dirs returns the directories in the frame to watch.
+func (f *frame) dirs() map[string]bool {
+	result := make(map[string]bool)
+	for _, files := range f.files {
+		for _, file := range files {
+			if file.IsDir() && file.Error() == nil {
+				result[file.Path()] = true
+			}
+		}
+	}
+	return result
+}
+
+// changed returns the paths which appeared or changed since prev.
+func (f *frame) changed(prev *frame) map[string]bool {
+	result := make(map[string]bool)
+	if prev == nil {
+		return result
+	}
+
+	for path, state := range f.states {
+		if prevState, ok := prev.states[path]; !ok || prevState != state {
+			result[path] = true
+		}
+	}
+	return result
+}
+
+// write draws the trees with the new formatter, so that no state is carried over from the previous frame.
+// The files whose paths are in highlights are highlighted, if the formatter supports it.
+func (f *frame) write(w io.Writer, opt *ListDisplayOptions, highlights map[string]bool) error {
+	tw, err := newTreeWriter(w, opt, newReport(opt))
+	if err != nil {
+		return err
+	}
+
+	if h, ok := tw.formatter.(highlighter); ok {
+		h.SetHighlights(highlights)
+	}
+
+	for _, files := range f.files {
+		if err := tw.writeFiles(files, opt.annotations); err != nil {
+			return err
+		}
+	}
+	return tw.close()
+}
+
+// updateWatches watches the directories, and stops watching the directories which don't exist anymore.
+func updateWatches(w watcher, dirs map[string]bool) error {
+	for _, path := range w.Watched() {
+		if !dirs[path] {
+			if err := w.Remove(path); err != nil {
+				return err
+			}
+		}
+	}
+
+	for path := range dirs {
+		// The directory deleted after the walk is skipped, since its parent notifies it, and the next frame drops it.
+		if err := w.Add(path); err != nil && !xerrors.Is(err, syscall.ENOENT) && !xerrors.Is(err, syscall.ENOTDIR) {
+			return err
+		}
+	}
+	return nil
+}
+
+// watchTree draws the trees, and redraws them whenever files under them change.
+// Files which appeared or changed since the previous frame are highlighted.
+func watchTree(ctx context.Context, roots []string, opts Options) error {
+	w, err := newWatcher()
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	terminal := isTerminal(os.Stdout)
+
+	var prev *frame
+	for {
+		f, err := newFrame(roots, opts)
+		if err != nil {
+			return err
+		}
+
+		if err := updateWatches(w, f.dirs()); err != nil {
+			return err
+		}
+
+		out := bufio.NewWriter(os.Stdout)
+		if terminal {
+			// Redraw in place.
+			fmt.Fprint(out, "\x1b[H\x1b[2J")
+		} else if prev != nil {
+			fmt.Fprintln(out)
+		}
+
+		changed := f.changed(prev)
+		prev = f
+
+		if err := f.write(out, opts.ListOptions.ListDisplayOptions, changed); err != nil {
+			return err
+		}
+		out.Flush()
+
+		if err := waitChanges(ctx, w); err != nil {
+			return err
+		}
+	}
+}
+
+// waitChanges waits a change, and the following changes until they settle down.
+func waitChanges(ctx context.Context, w watcher) error {
+	select {
+	case <-w.Events():
+	case err := <-w.Errors():
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(watchDebounce)
+	defer timer.Stop()
+	for {
+		select {
+		case <-w.Events():
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(watchDebounce)
+		case err := <-w.Errors():
+			return err
+		case <-timer.C:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
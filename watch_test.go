@@ -0,0 +1,159 @@
+package gtree
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"syscall"
+	"testing"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+type dummyWatcher struct {
+	watched map[string]bool
+
+	// errs is the errors of Add for paths.
+	errs map[string]error
+}
+
+func (d *dummyWatcher) Add(path string) error {
+	if err, ok := d.errs[path]; ok {
+		return err
+	}
+	d.watched[path] = true
+	return nil
+}
+
+func (d *dummyWatcher) Remove(path string) error {
+	delete(d.watched, path)
+	return nil
+}
+
+func (d *dummyWatcher) Watched() []string {
+	result := make([]string, 0, len(d.watched))
+	for path := range d.watched {
+		result = append(result, path)
+	}
+	return result
+}
+
+func (d *dummyWatcher) Events() <-chan string {
+	return nil
+}
+
+func (d *dummyWatcher) Errors() <-chan error {
+	return nil
+}
+
+func (d *dummyWatcher) Close() error {
+	return nil
+}
+
+func TestUpdateWatches(t *testing.T) {
+	w := &dummyWatcher{watched: map[string]bool{"a": true, "a/deleted": true}}
+
+	err := updateWatches(w, map[string]bool{"a": true, "a/created": true})
+	if err != nil {
+		t.Fatalf("updateWatches() returns error: %v", err)
+	}
+
+	got := w.Watched()
+	sort.Strings(got)
+	if expect := []string{"a", "a/created"}; !reflect.DeepEqual(got, expect) {
+		t.Errorf("updateWatches() expected %v, got %v", expect, got)
+	}
+}
+
+func TestUpdateWatches_Deleted(t *testing.T) {
+	w := &dummyWatcher{
+		watched: map[string]bool{},
+		errs: map[string]error{
+			"a/deleted": xerrors.Errorf("failed to watch a/deleted: %w", syscall.ENOENT),
+			"a/file":    xerrors.Errorf("failed to watch a/file: %w", syscall.ENOTDIR),
+		},
+	}
+
+	err := updateWatches(w, map[string]bool{"a": true, "a/deleted": true, "a/file": true})
+	if err != nil {
+		t.Fatalf("updateWatches() returns error: %v", err)
+	}
+	if got := w.Watched(); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Errorf("updateWatches() expected [a], got %v", got)
+	}
+
+	w.errs["a"] = xerrors.Errorf("failed to watch a: %w", syscall.ENOSPC)
+	w.watched = map[string]bool{}
+	if err := updateWatches(w, map[string]bool{"a": true}); err == nil {
+		t.Errorf("updateWatches() expected error for no space")
+	}
+}
+
+func TestFrame_Changed(t *testing.T) {
+	now := time.Now()
+	prev := &frame{states: map[string]fileState{
+		"a":         {},
+		"a/same":    {size: 1, modTime: now},
+		"a/written": {size: 1, modTime: now},
+		"a/deleted": {size: 1, modTime: now},
+	}}
+	f := &frame{states: map[string]fileState{
+		"a":         {},
+		"a/same":    {size: 1, modTime: now},
+		"a/written": {size: 2, modTime: now.Add(time.Second)},
+		"a/created": {size: 1, modTime: now},
+	}}
+
+	if got := f.changed(nil); len(got) != 0 {
+		t.Errorf("changed(nil) expected no paths, got %v", got)
+	}
+
+	expect := map[string]bool{"a/written": true, "a/created": true}
+	if got := f.changed(prev); !reflect.DeepEqual(got, expect) {
+		t.Errorf("changed() expected %v, got %v", expect, got)
+	}
+}
+
+func TestFrame_Write(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gtree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeTestFiles(t, dir, map[string]string{
+		"a/b/c.txt": "c",
+	})
+
+	opts := Options{ListOptions: &ListOptions{
+		ListSearchOptions:  &ListSearchOptions{},
+		ListDisplayOptions: &ListDisplayOptions{NoIcons: []bool{true}, Report: []bool{true}, Indent: 4},
+	}}
+	if err := validateOptions(opts); err != nil {
+		t.Fatal(err)
+	}
+
+	root := filepath.Join(dir, "a")
+	f, err := newFrame([]string{root}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := folderColor.Sprint(root) + "\n└── " + folderColor.Sprint("b") + "\n    └── " + changedColor.Sprint("c.txt") + "\n\n1 directory, 1 file\n"
+	highlights := map[string]bool{filepath.Join(root, "b", "c.txt"): true}
+
+	// Each frame is drawn from scratch, so that the same frame is drawn in the same way.
+	for i := 0; i < 2; i++ {
+		buffer := new(bytes.Buffer)
+		if err := f.write(buffer, opts.ListOptions.ListDisplayOptions, highlights); err != nil {
+			t.Fatal(err)
+		}
+		if got := buffer.String(); got != expect {
+			t.Errorf("frame.write() expected '%s', got '%s'", expect, got)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+//go:build linux
+// +build linux
+
+package gtree
+
+import (
+	"os"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/xerrors"
+)
+
+const inotifyMask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MODIFY | syscall.IN_ATTRIB |
+	syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO | syscall.IN_DELETE_SELF | syscall.IN_MOVE_SELF
+
+// inotifyWatcher watches directories by inotify.
+type inotifyWatcher struct {
+	// fd is used for watches instead of file.Fd, which may put the file into blocking mode,
+	// and then Close can't wake readEvents.
+	fd   int
+	file *os.File
+
+	mu    sync.Mutex
+	wds   map[string]int
+	paths map[int]string
+
+	events chan string
+	errors chan error
+	done   chan struct{}
+}
+
+func newWatcher() (watcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to initialize inotify: %w", err)
+	}
+
+	w := &inotifyWatcher{
+		fd:     fd,
+		file:   os.NewFile(uintptr(fd), "inotify"),
+		wds:    make(map[string]int),
+		paths:  make(map[int]string),
+		events: make(chan string),
+		errors: make(chan error),
+		done:   make(chan struct{}),
+	}
+	go w.readEvents()
+	return w, nil
+}
+
+func (w *inotifyWatcher) Add(path string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.wds[path]; ok {
+		return nil
+	}
+
+	wd, err := syscall.InotifyAddWatch(w.fd, path, inotifyMask)
+	if err != nil {
+		return xerrors.Errorf("failed to watch %s: %w", path, err)
+	}
+	w.wds[path] = wd
+	w.paths[wd] = path
+	return nil
+}
+
+func (w *inotifyWatcher) Remove(path string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	wd, ok := w.wds[path]
+	if !ok {
+		return nil
+	}
+	delete(w.wds, path)
+	delete(w.paths, wd)
+
+	// The watch is already removed, when the directory is deleted.
+	if _, err := syscall.InotifyRmWatch(w.fd, uint32(wd)); err != nil && err != syscall.EINVAL {
+		return xerrors.Errorf("failed to unwatch %s: %w", path, err)
+	}
+	return nil
+}
+
+func (w *inotifyWatcher) Watched() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	result := make([]string, 0, len(w.wds))
+	for path := range w.wds {
+		result = append(result, path)
+	}
+	return result
+}
+
+func (w *inotifyWatcher) Events() <-chan string {
+	return w.events
+}
+
+func (w *inotifyWatcher) Errors() <-chan error {
+	return w.errors
+}
+
+func (w *inotifyWatcher) Close() error {
+	close(w.done)
+	return w.file.Close()
+}
+
+func (w *inotifyWatcher) readEvents() {
+	defer close(w.events)
+	defer close(w.errors)
+
+	buf := make([]byte, syscall.SizeofInotifyEvent*4096)
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			select {
+			case w.errors <- xerrors.Errorf("failed to read inotify events: %w", err):
+			case <-w.done:
+			}
+			return
+		}
+
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameLen := int(event.Len)
+
+			var name string
+			if nameLen > 0 {
+				b := buf[offset+syscall.SizeofInotifyEvent : offset+syscall.SizeofInotifyEvent+nameLen]
+				for i, c := range b {
+					if c == 0 {
+						b = b[:i]
+						break
+					}
+				}
+				name = string(b)
+			}
+			offset += syscall.SizeofInotifyEvent + nameLen
+
+			w.mu.Lock()
+			path, ok := w.paths[int(event.Wd)]
+			if event.Mask&syscall.IN_IGNORED != 0 && ok {
+				delete(w.paths, int(event.Wd))
+				delete(w.wds, path)
+			}
+			w.mu.Unlock()
+
+			if !ok {
+				continue
+			}
+			if name != "" {
+				path += "/" + name
+			}
+			select {
+			case w.events <- path:
+			case <-w.done:
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+//go:build linux
+// +build linux
+
+package gtree
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestInotifyWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gtree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	w, err := newWatcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if err := w.Add(dir); err != nil {
+		t.Fatalf("Add() returns error: %v", err)
+	}
+
+	path := filepath.Join(dir, "new.txt")
+	if err := ioutil.WriteFile(path, []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case got := <-w.Events():
+		if got != path {
+			t.Errorf("Events() expected %s, got %s", path, got)
+		}
+	case err := <-w.Errors():
+		t.Fatalf("Errors() receives error: %v", err)
+	case <-time.After(time.Second):
+		t.Fatalf("Events() receives no event")
+	}
+
+	if err := w.Remove(dir); err != nil {
+		t.Fatalf("Remove() returns error: %v", err)
+	}
+	if got := w.Watched(); len(got) != 0 {
+		t.Errorf("Watched() expected no directories, got %v", got)
+	}
+}
+
+func TestInotifyWatcher_Close(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gtree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	w, err := newWatcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Add(dir); err != nil {
+		t.Fatalf("Add() returns error: %v", err)
+	}
+
+	// Receive an event, so that the goroutine reads the next events after Add.
+	if err := ioutil.WriteFile(filepath.Join(dir, "new.txt"), []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-w.Events():
+	case <-time.After(time.Second):
+		t.Fatalf("Events() receives no event")
+	}
+	for quiet := false; !quiet; {
+		select {
+		case <-w.Events():
+		case <-time.After(100 * time.Millisecond):
+			quiet = true
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() returns error: %v", err)
+	}
+
+	// The reading goroutine stops, and closes the channel.
+	select {
+	case _, ok := <-w.Events():
+		for ok {
+			_, ok = <-w.Events()
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Events() isn't closed after Close()")
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+// +build !linux
+
+package gtree
+
+import "golang.org/x/xerrors"
+
+func newWatcher() (watcher, error) {
+	return nil, xerrors.New("watch mode is supported only on linux")
+}
@@ -0,0 +1,83 @@
+package gtree
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestStringWidth(t *testing.T) {
+	tests := map[string]struct {
+		s         string
+		iconWidth int
+		width     int
+	}{
+		"ascii":              {"main.go", 1, 7},
+		"japanese":           {"日本語.txt", 1, 10},
+		"fullwidth":          {"ＡＢ", 1, 4},
+		"hangul":             {"한글", 1, 4},
+		"decomposed hangul":  {"\u1112\u1161\u11ab", 1, 2},
+		"combining mark":     {"e\u0301", 1, 1},
+		"emoji":              {"🎉", 1, 2},
+		"zwj sequence":       {"👩\u200d💻", 1, 2},
+		"skin tone":          {"👍🏽", 1, 2},
+		"variation selector": {"葛\U000e0100", 1, 2},
+		"icon":               {defaultFolderIcon.Icon + " src", 1, 5},
+		"double width icon":  {defaultFolderIcon.Icon + " src", 2, 6},
+		"box drawing":        {"├── ", 1, 4},
+	}
+
+	for key, tt := range tests {
+		t.Run(key, func(t *testing.T) {
+			if got := stringWidth(tt.s, tt.iconWidth); got != tt.width {
+				t.Errorf("stringWidth(%q) expected %d, got %d", tt.s, tt.width, got)
+			}
+		})
+	}
+}
+
+func TestPrinter_WriteAnnotationWide(t *testing.T) {
+	root := newDummyPrinterFileInfo("root", "root", "", "", true, true, nil, nil)
+	ja := newDummyPrinterFileInfo("資料.md", "root/資料.md", "md", "", false, false, nil, root)
+	en := newDummyPrinterFileInfo("doc.ml", "root/doc.ml", "ml", "", true, false, nil, root)
+	files := []FileInfo{root, ja, en}
+
+	tests := map[string]struct {
+		opt    *ListDisplayOptions
+		expect string
+	}{
+		"no icons": {
+			opt: &ListDisplayOptions{NoIcons: []bool{true}},
+			expect: "root\n" +
+				"├── 資料.md  # documents\n" +
+				"└── doc.ml   # english\n",
+		},
+		"single width icons": {
+			opt: &ListDisplayOptions{IconWidth: 1},
+			expect: defaultFolderIcon.Icon + " root\n" +
+				"├── " + icons["md"].Icon + " 資料.md  # documents\n" +
+				"└── " + icons["ml"].Icon + " doc.ml   # english\n",
+		},
+		"double width icons": {
+			opt: &ListDisplayOptions{IconWidth: 2},
+			expect: defaultFolderIcon.Icon + " root\n" +
+				"├── " + icons["md"].Icon + " 資料.md  # documents\n" +
+				"└── " + icons["ml"].Icon + " doc.ml    # english\n",
+		},
+	}
+
+	for key, tt := range tests {
+		t.Run(key, func(t *testing.T) {
+			p := NewPrinter(tt.opt)
+			p.SetAnnotations(annotations{"資料.md": "documents", "doc.ml": "english"}, files)
+
+			buffer := new(bytes.Buffer)
+			for _, f := range files {
+				p.Write(buffer, f)
+			}
+
+			if got := ansiEscape.ReplaceAllString(buffer.String(), ""); got != tt.expect {
+				t.Errorf("printer.Write() expected '%s', got '%s'", tt.expect, got)
+			}
+		})
+	}
+}